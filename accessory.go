@@ -0,0 +1,71 @@
+package minidrone
+
+// Accessory is the USB accessory attached to the drone.
+type Accessory int
+
+const (
+	AccessoryNone Accessory = iota
+	AccessoryClaw
+	AccessoryGun
+)
+
+func (a Accessory) String() string {
+	switch a {
+	case AccessoryNone:
+		return "none"
+	case AccessoryClaw:
+		return "claw"
+	case AccessoryGun:
+		return "gun"
+	}
+
+	return "unknown"
+}
+
+// Accessory returns the USB accessory reported by the drone, so that
+// ClawControl or GunControl are only used when the matching accessory is
+// attached. It is AccessoryNone until the drone has reported its states.
+func (m *Minidrone) Accessory() Accessory {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.accessory
+}
+
+func (m *Minidrone) processUsbAccessoryState(f frame) {
+	var kind Accessory
+	switch f.command {
+	case cmdClawState:
+		kind = AccessoryClaw
+	case cmdGunState:
+		kind = AccessoryGun
+	default:
+		return
+	}
+	if !f.argsLen(6) {
+		return
+	}
+
+	// an empty or removed entry means there is no accessory of this kind
+	attached := f.args[5]&(listFlagEmpty|listFlagRemove) == 0
+
+	m.stateMutex.Lock()
+	previous := m.accessory
+	switch {
+	case attached:
+		m.accessory = kind
+	case m.accessory == kind:
+		m.accessory = AccessoryNone
+	}
+	accessory := m.accessory
+	m.stateMutex.Unlock()
+
+	if accessory == previous {
+		return
+	}
+
+	if debug {
+		println("accessory", accessory.String())
+	}
+	m.publish(AccessoryChange, accessory)
+}
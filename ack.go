@@ -0,0 +1,87 @@
+package minidrone
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoAck is returned when the drone did not acknowledge a command after
+// all retries.
+var ErrNoAck = errors.New("command was not acknowledged")
+
+// WithAckedCommands sends commands such as TakeOff, Land and FlatTrim as
+// acknowledged frames. Each command waits up to timeout for the drone to
+// acknowledge it and is sent again up to retries times before ErrNoAck is
+// returned. A timeout of zero or less sends the commands unacknowledged, as
+// without the option.
+func WithAckedCommands(retries int, timeout time.Duration) Option {
+	return func(m *Minidrone) {
+		if timeout <= 0 {
+			m.ackRetries = -1
+			return
+		}
+		m.ackRetries = retries
+		m.ackTimeout = timeout
+	}
+}
+
+// writeAcked writes buf to the command characteristic as an acknowledged
+// frame, and waits for the ack with its sequence number. It must be called
+// with the commandMutex held.
+func (m *Minidrone) writeAcked(buf []byte) error {
+	buf[0] = frameTypeDataWithAck
+	seq := buf[1]
+
+	// discard acks that arrived late for earlier commands
+	for len(m.acks) > 0 {
+		<-m.acks
+	}
+
+	var err error
+	for attempt := 0; attempt <= m.ackRetries; attempt++ {
+		if debug && attempt > 0 {
+			println("retrying command", seq)
+		}
+
+		err = m.sendCommand(buf)
+		if err != nil {
+			continue
+		}
+
+		if m.waitAck(seq) {
+			return nil
+		}
+		err = ErrNoAck
+	}
+
+	return err
+}
+
+func (m *Minidrone) waitAck(seq byte) bool {
+	timeout := time.NewTimer(m.ackTimeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case acked := <-m.acks:
+			if acked == seq {
+				return true
+			}
+		case <-timeout.C:
+			return false
+		}
+	}
+}
+
+// processCommandAck handles an ack frame for a command, which carries the
+// sequence number of the acknowledged command.
+func (m *Minidrone) processCommandAck(data []byte) {
+	if len(data) < 3 || data[0] != frameTypeAck {
+		return
+	}
+
+	select {
+	case m.acks <- data[2]:
+	default:
+	}
+}
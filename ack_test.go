@@ -0,0 +1,74 @@
+package minidrone
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAckedCommands(t *testing.T) {
+	tests := []struct {
+		name string
+		// ack returns the sequence number to ack for attempt, or false to
+		// lose the ack
+		ack    func(attempt int, seq byte) (byte, bool)
+		err    error
+		writes int
+	}{
+		{
+			name:   "acked",
+			ack:    func(attempt int, seq byte) (byte, bool) { return seq, true },
+			writes: 1,
+		},
+		{
+			name:   "ack lost then retried",
+			ack:    func(attempt int, seq byte) (byte, bool) { return seq, attempt > 0 },
+			writes: 2,
+		},
+		{
+			name:   "stale ack",
+			ack:    func(attempt int, seq byte) (byte, bool) { return seq - 1, true },
+			err:    ErrNoAck,
+			writes: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ft := startFake(t)
+			WithAckedCommands(2, 20*time.Millisecond)(m)
+
+			writes := 0
+			ft.onCommand = func(frame []byte) {
+				if frame[0] != frameTypeDataWithAck {
+					t.Errorf("frame type = %d, want %d", frame[0], frameTypeDataWithAck)
+				}
+				seq, ok := tt.ack(writes, frame[1])
+				writes++
+				if ok {
+					ft.handler(ChannelCommandAck, []byte{frameTypeAck, byte(writes), seq})
+				}
+			}
+
+			err := m.TakeOff()
+			if err != tt.err {
+				t.Errorf("TakeOff() = %v, want %v", err, tt.err)
+			}
+			if writes != tt.writes {
+				t.Errorf("takeoff written %d times, want %d", writes, tt.writes)
+			}
+		})
+	}
+}
+
+func TestAckedCommandsNoTimeout(t *testing.T) {
+	m, ft := startFake(t)
+	WithAckedCommands(2, 0)(m)
+
+	err := m.TakeOff()
+	if err != nil {
+		t.Fatalf("TakeOff() = %v", err)
+	}
+	if c := ft.command(projectMinidrone, 0x00, 0x01); c[0] != frameTypeData {
+		t.Errorf("takeoff frame type = %d, want %d", c[0], frameTypeData)
+	}
+}
@@ -0,0 +1,33 @@
+package minidrone
+
+import "testing"
+
+// TestAllocs checks that the notifications sent many times per second and
+// the pcmd loop do not allocate, so that they do not trigger the garbage
+// collector of small boards in flight.
+func TestAllocs(t *testing.T) {
+	m, ft := startFake(t)
+	ft.discardPcmds = true
+
+	battery := []byte{frameTypeData, 1, projectCommon, classCommonState, cmdBatteryStateChanged, 0x00, 50}
+	flyingState := []byte{frameTypeData, 1, projectMinidrone, classPilotingState, cmdFlyingStateChanged, 0x00, byte(FlyingStateHovering), 0, 0, 0}
+	altitude := []byte{frameTypeData, 1, projectMinidrone, classNavigationDataState, cmdDroneAltitude, 0x00, 0xc4, 0x09, 0, 0}
+
+	tests := []struct {
+		name string
+		run  func()
+	}{
+		{"battery", func() { ft.handler(ChannelStatus, battery) }},
+		// the drone repeats the flying state far more often than it
+		// changes it, and only the changes allocate
+		{"flying state", func() { ft.handler(ChannelStatus, flyingState) }},
+		{"altitude", func() { ft.handler(ChannelStatus, altitude) }},
+		{"tick", m.Tick},
+	}
+
+	for _, tt := range tests {
+		if n := testing.AllocsPerRun(100, tt.run); n != 0 {
+			t.Errorf("%s: %v allocs, want 0", tt.name, n)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package minidrone
+
+type batteryThreshold struct {
+	percent int
+	fn      func(level int)
+	below   bool
+}
+
+// OnBatteryBelow calls fn with the battery level when it drops below
+// percent. It can be used more than once to add several thresholds, such as
+// a warning at 30% and a landing at 15%. Each fn is called once when the
+// level crosses its threshold, and again only after the battery was charged
+// above it.
+func (m *Minidrone) OnBatteryBelow(percent int, fn func(level int)) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.batteryThresholds = append(m.batteryThresholds, &batteryThreshold{
+		percent: percent,
+		fn:      fn,
+	})
+}
+
+// checkBattery calls the thresholds crossed by level.
+func (m *Minidrone) checkBattery(level int) {
+	m.stateMutex.Lock()
+	var crossed []*batteryThreshold
+	for _, t := range m.batteryThresholds {
+		below := level < t.percent
+		if below && !t.below {
+			crossed = append(crossed, t)
+		}
+		t.below = below
+	}
+	m.stateMutex.Unlock()
+
+	for _, t := range crossed {
+		t.fn(level)
+	}
+}
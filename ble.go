@@ -0,0 +1,380 @@
+package minidrone
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+var (
+	// BLE services
+	droneCommandServiceUUID      = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x00, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+	droneNotificationServiceUUID = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfb, 0x00, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+
+	// send characteristics
+	pcmdCharacteristicUUID            = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x0a, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+	commandCharacteristicUUID         = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x0b, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+	priorityCharacteristicUUID        = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x0c, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+	notificationAckCharacteristicUUID = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x1e, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+
+	// receive characteristics
+	flightStatusCharacteristicUUID         = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfb, 0x0e, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+	batteryCharacteristicUUID              = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfb, 0x0f, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+	commandAckCharacteristicUUID           = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfb, 0x1b, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+	priorityNotificationCharacteristicUUID = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfb, 0x1c, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+
+	// handshake services and characteristics of newer firmwares
+	handshakeServiceUUIDs = []bluetooth.UUID{
+		bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfd, 0x21, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e}),
+		bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfd, 0x51, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e}),
+	}
+	handshakeCharacteristicUUIDs = []bluetooth.UUID{
+		bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfd, 0x22, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e}),
+		bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfd, 0x23, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e}),
+		bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfd, 0x24, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e}),
+		bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfd, 0x52, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e}),
+		bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfd, 0x53, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e}),
+		bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfd, 0x54, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e}),
+	}
+)
+
+// ErrFrameTooLarge is returned when a frame does not fit in a single write at
+// the negotiated ATT MTU. The minidrone BLE protocol has no fragmentation:
+// the drone parses every write as one whole frame, so a frame split across
+// writes would arrive as two broken frames.
+var ErrFrameTooLarge = errors.New("frame is larger than the BLE MTU")
+
+// defaultMTU is the ATT MTU every BLE connection starts with, and
+// attHeaderLen is the part of it used by the ATT write header.
+const (
+	defaultMTU   = 23
+	attHeaderLen = 3
+)
+
+// LowLatencyParams are the shortest connection intervals allowed by the
+// BLE spec. They can be passed to Adapter.Connect, or requested after
+// connecting with RequestLowLatency.
+var LowLatencyParams = bluetooth.ConnectionParams{
+	MinInterval: bluetooth.NewDuration(7500 * time.Microsecond),
+	MaxInterval: bluetooth.NewDuration(15 * time.Millisecond),
+}
+
+// BLETransport is the Transport for a drone connected over Bluetooth LE.
+type BLETransport struct {
+	device                             *bluetooth.Device
+	commandService                     *bluetooth.DeviceService
+	commandCharacteristic              *bluetooth.DeviceCharacteristic
+	pcmdCharacteristic                 *bluetooth.DeviceCharacteristic
+	priorityCharacteristic             *bluetooth.DeviceCharacteristic
+	notificationAckCharacteristic      *bluetooth.DeviceCharacteristic
+	notificationService                *bluetooth.DeviceService
+	flightStatusCharacteristic         *bluetooth.DeviceCharacteristic
+	batteryCharacteristic              *bluetooth.DeviceCharacteristic
+	commandAckCharacteristic           *bluetooth.DeviceCharacteristic
+	priorityNotificationCharacteristic *bluetooth.DeviceCharacteristic
+	handshakeCharacteristics           []bluetooth.DeviceCharacteristic
+
+	mtu       uint16
+	stepsfa1e uint8
+	ackBuf    [3]byte
+}
+
+// NewBLETransport returns a new BLETransport for the connected device.
+func NewBLETransport(dev *bluetooth.Device) *BLETransport {
+	return &BLETransport{
+		device: dev,
+	}
+}
+
+// Connect discovers the drone services and characteristics, giving up when
+// ctx is done first. The bluetooth package cannot cancel a discovery that is
+// under way, so ctx is only checked between the discovery steps.
+func (t *BLETransport) Connect(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return t.discover(ctx)
+}
+
+// discover finds the drone services and characteristics.
+func (t *BLETransport) discover(ctx context.Context) error {
+	srvcs, err := t.device.DiscoverServices([]bluetooth.UUID{
+		droneCommandServiceUUID,
+		droneNotificationServiceUUID,
+	})
+	switch {
+	case err != nil:
+		return err
+	case len(srvcs) == 0:
+		return errors.New("could not find drone services")
+	}
+
+	t.commandService = &srvcs[0]
+	t.notificationService = &srvcs[1]
+	if debug {
+		println("found drone command service", t.commandService.UUID().String())
+		println("found drone notify service", t.notificationService.UUID().String())
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	chars, err := t.commandService.DiscoverCharacteristics([]bluetooth.UUID{
+		commandCharacteristicUUID,
+		pcmdCharacteristicUUID,
+		priorityCharacteristicUUID,
+	})
+	switch {
+	case err != nil:
+		return err
+	case len(chars) < 3:
+		return errors.New("could not find drone command characteristics")
+	}
+
+	if debug {
+		println("found drone command characteristics", chars[0].UUID().String(), chars[1].UUID().String(), chars[2].UUID().String())
+	}
+	t.commandCharacteristic = &chars[0]
+	t.pcmdCharacteristic = &chars[1]
+	t.priorityCharacteristic = &chars[2]
+
+	// the drone protocol has no fragmentation over BLE, so every frame must
+	// fit in a single write
+	t.mtu = defaultMTU
+	mtu, err := t.commandCharacteristic.GetMTU()
+	if err == nil && mtu > defaultMTU {
+		t.mtu = mtu
+	}
+	if debug {
+		println("mtu", t.mtu)
+	}
+
+	// notifications that require an ack are acknowledged on fa1e, when the
+	// drone has it
+	chars, err = t.commandService.DiscoverCharacteristics([]bluetooth.UUID{
+		notificationAckCharacteristicUUID,
+	})
+	if err == nil && len(chars) > 0 {
+		t.notificationAckCharacteristic = &chars[0]
+	} else if debug {
+		println("no notification ack characteristic")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	chars, err = t.notificationService.DiscoverCharacteristics([]bluetooth.UUID{
+		flightStatusCharacteristicUUID,
+		batteryCharacteristicUUID,
+	})
+	switch {
+	case err != nil:
+		return err
+	case len(chars) < 2:
+		return errors.New("could not find drone notify characteristics")
+	}
+
+	if debug {
+		println("found drone notify characteristics", chars[0].UUID().String(), chars[1].UUID().String())
+	}
+	t.flightStatusCharacteristic = &chars[0]
+	t.batteryCharacteristic = &chars[1]
+
+	// critical alerts and emergencies are sent on fb1c, when the drone has it
+	chars, err = t.notificationService.DiscoverCharacteristics([]bluetooth.UUID{
+		priorityNotificationCharacteristicUUID,
+	})
+	if err == nil && len(chars) > 0 {
+		t.priorityNotificationCharacteristic = &chars[0]
+	} else if debug {
+		println("no priority notification characteristic")
+	}
+
+	// acks for the commands are sent on fb1b, when the drone has it
+	chars, err = t.notificationService.DiscoverCharacteristics([]bluetooth.UUID{
+		commandAckCharacteristicUUID,
+	})
+	if err == nil && len(chars) > 0 {
+		t.commandAckCharacteristic = &chars[0]
+	} else if debug {
+		println("no command ack characteristic")
+	}
+
+	t.discoverHandshake()
+
+	return nil
+}
+
+// discoverHandshake looks for the fd21 and fd51 services. Newer firmwares,
+// such as those of the Mambo, only accept piloting commands reliably once
+// notifications are enabled on their characteristics. Older drones do not
+// have them, which is fine.
+func (t *BLETransport) discoverHandshake() {
+	t.handshakeCharacteristics = t.handshakeCharacteristics[:0]
+	for _, uuid := range handshakeServiceUUIDs {
+		srvcs, err := t.device.DiscoverServices([]bluetooth.UUID{uuid})
+		if err != nil || len(srvcs) == 0 {
+			continue
+		}
+
+		chars, err := srvcs[0].DiscoverCharacteristics(handshakeCharacteristicUUIDs)
+		if err != nil {
+			continue
+		}
+		t.handshakeCharacteristics = append(t.handshakeCharacteristics, chars...)
+	}
+
+	if debug {
+		println("found handshake characteristics", len(t.handshakeCharacteristics))
+	}
+}
+
+// Subscribe enables the notifications of the drone.
+func (t *BLETransport) Subscribe(handler func(ch Channel, frame []byte)) (err error) {
+	// a new connection starts over from the first sequence number
+	t.stepsfa1e = 0
+
+	if t.commandAckCharacteristic != nil {
+		if debug {
+			println("enabling command ack notifications")
+		}
+
+		err = t.commandAckCharacteristic.EnableNotifications(func(buf []byte) {
+			handler(ChannelCommandAck, buf)
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	if debug {
+		println("enabling pcmd notifications")
+	}
+
+	err = t.flightStatusCharacteristic.EnableNotifications(func(buf []byte) {
+		t.ackNotification(buf)
+		handler(ChannelStatus, buf)
+	})
+	if err != nil {
+		return
+	}
+
+	if debug {
+		println("enabling battery notifications")
+	}
+
+	err = t.batteryCharacteristic.EnableNotifications(func(buf []byte) {
+		handler(ChannelData, buf)
+	})
+	if err != nil {
+		return
+	}
+
+	if t.priorityNotificationCharacteristic != nil {
+		if debug {
+			println("enabling priority notifications")
+		}
+
+		err = t.priorityNotificationCharacteristic.EnableNotifications(func(buf []byte) {
+			handler(ChannelPriority, buf)
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	// the handshake notifications carry nothing the driver uses, enabling
+	// them is what the drone is waiting for
+	for i := range t.handshakeCharacteristics {
+		herr := t.handshakeCharacteristics[i].EnableNotifications(func(buf []byte) {})
+		if herr != nil && debug {
+			println("handshake notification error", herr.Error())
+		}
+	}
+
+	return
+}
+
+// ackNotification acknowledges a notification that requires an ack, by
+// sending its sequence number back on the notification ack characteristic.
+// The drone stops sending such notifications when they are not acknowledged.
+func (t *BLETransport) ackNotification(data []byte) {
+	if t.notificationAckCharacteristic == nil || len(data) < 2 || data[0] != frameTypeDataWithAck {
+		return
+	}
+
+	t.stepsfa1e++
+	t.ackBuf = [3]byte{frameTypeAck, t.stepsfa1e, data[1]}
+	_, err := t.notificationAckCharacteristic.WriteWithoutResponse(t.ackBuf[:])
+	if err != nil && debug {
+		println("notification ack error", err.Error())
+	}
+}
+
+// MaxFrameLen returns the largest frame that fits in a single write at the
+// negotiated MTU.
+func (t *BLETransport) MaxFrameLen() int {
+	if t.mtu == 0 {
+		return defaultMTU - attHeaderLen
+	}
+
+	return int(t.mtu) - attHeaderLen
+}
+
+// WriteCommand writes frame to the fa0b command characteristic.
+func (t *BLETransport) WriteCommand(frame []byte) error {
+	return t.write(t.commandCharacteristic, frame)
+}
+
+// WritePcmd writes frame to the fa0a pcmd characteristic.
+func (t *BLETransport) WritePcmd(frame []byte) error {
+	return t.write(t.pcmdCharacteristic, frame)
+}
+
+// WritePriority writes frame to the fa0c priority characteristic.
+func (t *BLETransport) WritePriority(frame []byte) error {
+	return t.write(t.priorityCharacteristic, frame)
+}
+
+// write writes frame to c, rejecting frames that do not fit in one write
+// rather than letting the drone receive them truncated. All the commands the
+// driver builds fit in the 20 bytes of the default MTU.
+func (t *BLETransport) write(c *bluetooth.DeviceCharacteristic, frame []byte) error {
+	if len(frame) > t.MaxFrameLen() {
+		return ErrFrameTooLarge
+	}
+
+	_, err := c.WriteWithoutResponse(frame)
+	return err
+}
+
+// RequestLowLatency asks the drone for shorter connection intervals, so that
+// pcmd frames reach it sooner. Whether they are used depends on the adapter:
+// BlueZ on Linux, for example, ignores the request.
+func (t *BLETransport) RequestLowLatency() error {
+	return t.device.RequestConnectionParams(LowLatencyParams)
+}
+
+// requestLowLatency requests low latency connection parameters when the
+// drone is connected over BLE.
+func (m *Minidrone) requestLowLatency() {
+	t, ok := m.transport.(*BLETransport)
+	if !ok {
+		return
+	}
+
+	err := t.RequestLowLatency()
+	if err != nil && debug {
+		println("low latency error", err.Error())
+	}
+}
+
+// Disconnect disconnects the device.
+func (t *BLETransport) Disconnect() error {
+	return t.device.Disconnect()
+}
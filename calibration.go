@@ -0,0 +1,112 @@
+package minidrone
+
+// CalibrationAxis is the axis the drone is waiting to be rotated around
+// during a magnetometer calibration.
+type CalibrationAxis int
+
+const (
+	CalibrationAxisX CalibrationAxis = iota
+	CalibrationAxisY
+	CalibrationAxisZ
+	CalibrationAxisNone
+)
+
+func (a CalibrationAxis) String() string {
+	switch a {
+	case CalibrationAxisX:
+		return "x"
+	case CalibrationAxisY:
+		return "y"
+	case CalibrationAxisZ:
+		return "z"
+	case CalibrationAxisNone:
+		return "none"
+	}
+
+	return "unknown"
+}
+
+// Calibration is the magnetometer calibration state reported by the drone.
+type Calibration struct {
+	// Required is true when the drone must be calibrated before flight.
+	Required bool
+
+	// Started is true while a calibration is in progress.
+	Started bool
+
+	// Axis is the axis to calibrate next.
+	Axis CalibrationAxis
+
+	// X, Y and Z are true once each axis has been calibrated.
+	X, Y, Z bool
+
+	// Failed is true when the last calibration failed.
+	Failed bool
+}
+
+// Calibration returns the last calibration state reported by the drone.
+func (m *Minidrone) Calibration() Calibration {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.calibration
+}
+
+// StartCalibration starts a magnetometer calibration. Follow the Axis of
+// CalibrationChange events to rotate the drone around each axis in turn.
+func (m *Minidrone) StartCalibration() error {
+	return m.magnetoCalibration(true)
+}
+
+// CancelCalibration aborts a magnetometer calibration in progress.
+func (m *Minidrone) CancelCalibration() error {
+	return m.magnetoCalibration(false)
+}
+
+func (m *Minidrone) magnetoCalibration(start bool) error {
+	buf := []byte{0x02, 0x00, 0x00, 0x0d, 0x00, 0x00, boolByte(start)}
+	return m.writeCommand("calibration", buf)
+}
+
+func (m *Minidrone) processCalibrationState(f frame) {
+	calibration, wasRequired, ok := m.updateCalibration(f)
+	if !ok {
+		return
+	}
+
+	if debug {
+		println("calibration required", calibration.Required, "axis", calibration.Axis.String())
+	}
+	m.publish(CalibrationChange, calibration)
+	if calibration.Required && !wasRequired {
+		m.publish(CalibrationRequired, calibration)
+	}
+}
+
+// updateCalibration applies a CalibrationState frame, and returns the new
+// state and whether calibration was required before it.
+func (m *Minidrone) updateCalibration(f frame) (c Calibration, wasRequired, ok bool) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	c = m.calibration
+	wasRequired = c.Required
+	switch {
+	case f.command == cmdMagnetoCalibrationStateChanged && f.argsLen(4):
+		c.X = f.args[0] != 0
+		c.Y = f.args[1] != 0
+		c.Z = f.args[2] != 0
+		c.Failed = f.args[3] != 0
+	case f.command == cmdMagnetoCalibrationRequiredState && f.argsLen(1):
+		c.Required = f.args[0] != 0
+	case f.command == cmdMagnetoCalibrationAxisChanged && f.argsLen(4):
+		c.Axis = CalibrationAxis(f.uint32At(0))
+	case f.command == cmdMagnetoCalibrationStartedChanged && f.argsLen(1):
+		c.Started = f.args[0] != 0
+	default:
+		return c, wasRequired, false
+	}
+	m.calibration = c
+
+	return c, wasRequired, true
+}
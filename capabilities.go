@@ -0,0 +1,76 @@
+package minidrone
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrFlipUnsupported is returned by the flip commands when the drone model
+// cannot flip, or cannot flip in its current flying mode.
+var ErrFlipUnsupported = errors.New("flips are not supported by this drone")
+
+// Capabilities are the features supported by a drone model.
+type Capabilities struct {
+	// Model is the name of the drone model, such as "Mambo".
+	Model string
+
+	// Flips is true when the drone can do the flip animations.
+	Flips bool
+
+	// Headlights is true when the drone has headlights that can be set
+	// with LightControl or Headlights.
+	Headlights bool
+}
+
+// models are the default product name prefixes of the minidrones
+var models = []struct {
+	prefix string
+	caps   Capabilities
+}{
+	{"rs_", Capabilities{Model: "Rolling Spider", Flips: true}},
+	{"mambo", Capabilities{Model: "Mambo", Flips: true}},
+	{"swing", Capabilities{Model: "Swing", Flips: true}},
+	{"mars", Capabilities{Model: "Airborne Cargo Mars", Flips: true}},
+	{"travis", Capabilities{Model: "Airborne Cargo Travis", Flips: true}},
+	{"maclane", Capabilities{Model: "Airborne Night Maclane", Flips: true, Headlights: true}},
+	{"blaze", Capabilities{Model: "Airborne Night Blaze", Flips: true, Headlights: true}},
+	{"swat", Capabilities{Model: "Airborne Night Swat", Flips: true, Headlights: true}},
+	{"orak", Capabilities{Model: "Hydrofoil Orak"}},
+	{"newz", Capabilities{Model: "Hydrofoil NewZ"}},
+}
+
+// Capabilities returns the features of the drone model, found from the
+// product name it reported in its settings. ok is false when the model is
+// not known yet, or when the drone was renamed so that its model cannot be
+// told from its name.
+func (m *Minidrone) Capabilities() (caps Capabilities, ok bool) {
+	name := strings.ToLower(m.Product().Name)
+	if name == "" {
+		return caps, false
+	}
+
+	for _, model := range models {
+		if strings.HasPrefix(name, model.prefix) {
+			return model.caps, true
+		}
+	}
+
+	return caps, false
+}
+
+// checkFlip returns an error when the drone is known to be unable to flip.
+func (m *Minidrone) checkFlip() error {
+	if m.noFlips {
+		return ErrFlipsDisabled
+	}
+
+	caps, ok := m.Capabilities()
+	if ok && !caps.Flips {
+		return ErrFlipUnsupported
+	}
+	if m.PilotingModes().FlyingMode != FlyingModeQuad {
+		return ErrFlipUnsupported
+	}
+
+	return nil
+}
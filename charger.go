@@ -0,0 +1,61 @@
+package minidrone
+
+// ChargeState is the charging state of the battery.
+type ChargeState int
+
+const (
+	ChargeDischarging ChargeState = iota
+	ChargeSlow
+	ChargeFast
+	ChargeFull
+)
+
+func (c ChargeState) String() string {
+	switch c {
+	case ChargeDischarging:
+		return "discharging"
+	case ChargeSlow:
+		return "charging slow"
+	case ChargeFast:
+		return "charging fast"
+	case ChargeFull:
+		return "full"
+	}
+
+	return "unknown"
+}
+
+// Charging returns true when the drone is on the charger and its battery is
+// charging or full.
+func (c ChargeState) Charging() bool {
+	return c != ChargeDischarging
+}
+
+// Charge returns the last charging state reported by the drone.
+func (m *Minidrone) Charge() ChargeState {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.charge
+}
+
+func (m *Minidrone) processChargerState(f frame) {
+	if f.command != cmdCurrentChargeStateChanged || !f.argsLen(4) {
+		return
+	}
+	charge := ChargeState(f.uint32At(0))
+
+	m.stateMutex.Lock()
+	changed := charge != m.charge
+	m.charge = charge
+	m.stateMutex.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if debug {
+		println("charge", charge.String())
+	}
+	m.publish(ChargeChange, charge)
+}
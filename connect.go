@@ -0,0 +1,84 @@
+package minidrone
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// DefaultConnectTimeout is how long Connect waits to find, connect to and
+// start the drone.
+const DefaultConnectTimeout = 10 * time.Second
+
+var errScanStopped = errors.New("scan stopped before the drone was found")
+
+// Connect scans for the drone with address, such as "4C:D2:6C:17:82:6E",
+// connects to it and starts it, configured with any of the given options.
+// It gives up after DefaultConnectTimeout. The adapter must already be
+// enabled.
+func Connect(adapter *bluetooth.Adapter, address string, opts ...Option) (*Minidrone, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultConnectTimeout)
+	defer cancel()
+
+	return ConnectContext(ctx, adapter, address, opts...)
+}
+
+// ConnectContext is like Connect, but gives up when ctx is done instead.
+func ConnectContext(ctx context.Context, adapter *bluetooth.Adapter, address string, opts ...Option) (*Minidrone, error) {
+	result, err := scanAddress(ctx, adapter, address)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := adapter.Connect(result.Address, bluetooth.ConnectionParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	m := NewMinidrone(&device, opts...)
+	err = m.StartContext(ctx)
+	if err != nil {
+		device.Disconnect()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// scanAddress scans until the device with address is found, or ctx is done.
+func scanAddress(ctx context.Context, adapter *bluetooth.Adapter, address string) (result bluetooth.ScanResult, err error) {
+	found := make(chan bluetooth.ScanResult, 1)
+	scanned := make(chan error, 1)
+	go func() {
+		scanned <- adapter.Scan(func(a *bluetooth.Adapter, result bluetooth.ScanResult) {
+			if result.Address.String() == address {
+				select {
+				case found <- result:
+				default:
+				}
+				a.StopScan()
+			}
+		})
+	}()
+
+	select {
+	case result = <-found:
+	case err = <-scanned:
+		if err != nil {
+			return result, err
+		}
+		// the scan stops once the drone is found, which is sent first
+		select {
+		case result = <-found:
+		default:
+			return result, errScanStopped
+		}
+	case <-ctx.Done():
+		adapter.StopScan()
+		return result, ctx.Err()
+	}
+
+	return result, nil
+}
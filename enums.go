@@ -0,0 +1,106 @@
+package minidrone
+
+// FlyingState is the flying state reported by the drone.
+type FlyingState int
+
+const (
+	FlyingStateLanded FlyingState = iota
+	FlyingStateTakeoff
+	FlyingStateHovering
+	FlyingStateFlying
+	FlyingStateLanding
+	FlyingStateEmergency
+	FlyingStateRolling
+)
+
+// String returns the name of the flying state, which is also the name of
+// the event published when the drone reaches it.
+func (s FlyingState) String() string {
+	switch s {
+	case FlyingStateLanded:
+		return Landed
+	case FlyingStateTakeoff:
+		return Takeoff
+	case FlyingStateHovering:
+		return Hovering
+	case FlyingStateFlying:
+		return Flying
+	case FlyingStateLanding:
+		return Landing
+	case FlyingStateEmergency:
+		return Emergency
+	case FlyingStateRolling:
+		return Rolling
+	}
+
+	return "unknown"
+}
+
+// MarshalText encodes the flying state by name, such as in JSON.
+func (s FlyingState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// PilotingEvent is the piloting state change passed to the handler set with
+// OnPilotingEvent.
+type PilotingEvent int
+
+const (
+	PilotingEventFlatTrimChanged PilotingEvent = iota
+	PilotingEventFlyingStateChanged
+)
+
+// PilotingStateFlatTrimChanged and PilotingStateFlyingStateChanged are the
+// states passed to the handler set with PilotingStateChange.
+const (
+	PilotingStateFlatTrimChanged    = int(PilotingEventFlatTrimChanged)
+	PilotingStateFlyingStateChanged = int(PilotingEventFlyingStateChanged)
+)
+
+func (e PilotingEvent) String() string {
+	switch e {
+	case PilotingEventFlatTrimChanged:
+		return "flattrimchanged"
+	case PilotingEventFlyingStateChanged:
+		return "flyingstatechanged"
+	}
+
+	return "unknown"
+}
+
+// MarshalText encodes the piloting event by name, such as in JSON.
+func (e PilotingEvent) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// Animation is an animation the drone can perform, such as a flip.
+type Animation int
+
+const (
+	AnimationFrontFlip Animation = iota
+	AnimationBackFlip
+	AnimationRightFlip
+	AnimationLeftFlip
+)
+
+// String returns the name of the animation, which is also the name of the
+// command sent for it.
+func (a Animation) String() string {
+	switch a {
+	case AnimationFrontFlip:
+		return "frontflip"
+	case AnimationBackFlip:
+		return "backflip"
+	case AnimationRightFlip:
+		return "rightflip"
+	case AnimationLeftFlip:
+		return "leftflip"
+	}
+
+	return "unknown"
+}
+
+// MarshalText encodes the animation by name, such as in JSON.
+func (a Animation) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
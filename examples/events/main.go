@@ -49,12 +49,12 @@ func main() {
 	defer device.Disconnect()
 
 	drone = minidrone.NewMinidrone(&device)
-	drone.PilotingStateChange(func(state, substate int) {
-		switch state {
-		case minidrone.PilotingStateFlyingStateChanged:
-			println("FlightStateChange", minidrone.FlyingState(substate))
+	drone.OnPilotingEvent(func(event minidrone.PilotingEvent, state minidrone.FlyingState) {
+		switch event {
+		case minidrone.PilotingEventFlyingStateChanged:
+			println("FlightStateChange", state.String())
 		default:
-			println("PilotingStateChange", state, substate)
+			println("PilotingStateChange", event.String(), state.String())
 		}
 	})
 
@@ -5,11 +5,6 @@ import (
 	"time"
 )
 
-type pair struct {
-	x int
-	y int
-}
-
 const (
 	// frameX    = 400
 	// frameY    = 300
@@ -18,20 +13,6 @@ const (
 	detente = 20000
 )
 
-func getLeftStick() pair {
-	s := pair{x: 0, y: 0}
-	s.x = leftX
-	s.y = leftY
-	return s
-}
-
-func getRightStick() pair {
-	s := pair{x: 0, y: 0}
-	s.x = rightX
-	s.y = rightY
-	return s
-}
-
 func initPins() {
 	// buttons
 	b1.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	minidrone "github.com/hybridgroup/tinygo-minidrone"
+	"github.com/hybridgroup/tinygo-minidrone/stick"
 	"tinygo.org/x/bluetooth"
 )
 
@@ -33,10 +34,35 @@ var (
 	device  *bluetooth.Device
 	ch      = make(chan bluetooth.ScanResult, 1)
 
-	drone *minidrone.Minidrone
-	speed = 20
+	drone  *minidrone.Minidrone
+	speed  = 20
+	sticks = newSticks()
 )
 
+// newSticks ignores the sticks until they are pushed past the detente, like
+// the controls always did.
+func newSticks() stick.Sticks {
+	axis := stick.ADC()
+	axis.Deadzone = float64(detente) / center
+	return stick.Sticks{
+		Left:  stick.Stick{X: axis, Y: axis},
+		Right: stick.Stick{X: axis, Y: axis},
+	}
+}
+
+// fixedSpeed moves at speed in the direction of a stick pushed past the
+// detente, however far it is pushed.
+func fixedSpeed(percent int) int {
+	switch {
+	case percent > 0:
+		return speed
+	case percent < 0:
+		return -speed
+	}
+
+	return 0
+}
+
 func main() {
 	machine.I2C0.Configure(machine.I2CConfig{})
 
@@ -79,45 +105,8 @@ func scanHandler(a *bluetooth.Adapter, d bluetooth.ScanResult) {
 
 func controlDrone() {
 	for {
-		rightStick := getRightStick()
-
-		switch {
-		case rightStick.y+detente < center:
-			drone.Backward(speed)
-		case rightStick.y-detente > center:
-			drone.Forward(speed)
-		default:
-			drone.Forward(0)
-		}
-
-		switch {
-		case rightStick.x-detente > center:
-			drone.Right(speed)
-		case rightStick.x+detente < center:
-			drone.Left(speed)
-		default:
-			drone.Right(0)
-		}
-
-		leftStick := getLeftStick()
-
-		switch {
-		case leftStick.y+detente < center:
-			drone.Down(speed)
-		case leftStick.y-detente > center:
-			drone.Up(speed)
-		default:
-			drone.Up(0)
-		}
-
-		switch {
-		case leftStick.x-detente > center:
-			drone.Clockwise(speed)
-		case leftStick.x+detente < center:
-			drone.CounterClockwise(speed)
-		default:
-			drone.Clockwise(0)
-		}
+		p := sticks.Pcmd(leftX, leftY, rightX, rightY)
+		drone.Move(fixedSpeed(p.Roll), fixedSpeed(p.Pitch), fixedSpeed(p.Yaw), fixedSpeed(p.Gaz))
 
 		time.Sleep(100 * time.Millisecond)
 	}
@@ -0,0 +1,153 @@
+package minidrone
+
+// FailsafeAction is what the Minidrone does when a FailsafeRule is triggered.
+type FailsafeAction int
+
+const (
+	// FailsafeWarn only publishes a Failsafe event.
+	FailsafeWarn FailsafeAction = iota
+
+	// FailsafeHover stops all movement and hovers in place.
+	FailsafeHover
+
+	// FailsafeLand lands the drone.
+	FailsafeLand
+
+	// FailsafeEmergency cuts the motors.
+	FailsafeEmergency
+)
+
+// Condition is a check over the current Status of the Minidrone.
+type Condition func(s Status) bool
+
+// FailsafeRule maps a Condition to the FailsafeAction to take when it is met.
+type FailsafeRule struct {
+	Name      string
+	Condition Condition
+	Action    FailsafeAction
+}
+
+// SetFailsafe sets the rules that are evaluated against the drone Status on
+// every pcmd cycle, in addition to any rules added by options. A rule is
+// triggered each time its condition becomes true, and publishes a Failsafe
+// event with the rule before taking its action.
+func (m *Minidrone) SetFailsafe(rules ...FailsafeRule) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.userRules = rules
+	m.updateFailsafe()
+}
+
+// addPresetFailsafe adds a rule that is kept when SetFailsafe is called,
+// for use by options.
+func (m *Minidrone) addPresetFailsafe(rule FailsafeRule) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	m.presetRules = append(m.presetRules, rule)
+	m.updateFailsafe()
+}
+
+// updateFailsafe must be called with the stateMutex held.
+func (m *Minidrone) updateFailsafe() {
+	rules := make([]FailsafeRule, 0, len(m.presetRules)+len(m.userRules))
+	rules = append(rules, m.presetRules...)
+	rules = append(rules, m.userRules...)
+
+	m.failsafeRules = rules
+	m.failsafeActive = make([]bool, len(rules))
+}
+
+func (m *Minidrone) evaluateFailsafe() {
+	m.stateMutex.Lock()
+	rules, active := m.failsafeRules, m.failsafeActive
+	m.stateMutex.Unlock()
+
+	if len(rules) == 0 {
+		return
+	}
+
+	s := m.Status()
+	for i, rule := range rules {
+		met := rule.Condition(s)
+		if !met || active[i] {
+			active[i] = met
+			continue
+		}
+		active[i] = true
+
+		if debug {
+			println("failsafe", rule.Name)
+		}
+		m.publish(Failsafe, rule)
+
+		switch rule.Action {
+		case FailsafeHover:
+			m.hoverNow()
+		case FailsafeLand:
+			m.landFromTick()
+		case FailsafeEmergency:
+			m.Emergency()
+		}
+	}
+}
+
+// BatteryBelow is met when the battery level is known and below percent.
+func BatteryBelow(percent int) Condition {
+	return func(s Status) bool {
+		return s.Battery >= 0 && s.Battery < percent
+	}
+}
+
+// RSSIBelow is met when the signal strength is known and below rssi.
+func RSSIBelow(rssi int16) Condition {
+	return func(s Status) bool {
+		return s.RSSI != 0 && s.RSSI < rssi
+	}
+}
+
+// LinkErrorsAbove is met when more than count pcmd writes have failed.
+func LinkErrorsAbove(count int) Condition {
+	return func(s Status) bool {
+		return s.LinkErrors > count
+	}
+}
+
+// AltitudeAbove is met when the reported altitude is above millimeters.
+func AltitudeAbove(millimeters int32) Condition {
+	return func(s Status) bool {
+		return s.Telemetry.Altitude > millimeters
+	}
+}
+
+// WhileFlying is met while the drone is flying.
+func WhileFlying() Condition {
+	return func(s Status) bool {
+		return s.Flying
+	}
+}
+
+// All is met when every one of the conditions is met.
+func All(conditions ...Condition) Condition {
+	return func(s Status) bool {
+		for _, c := range conditions {
+			if !c(s) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any is met when at least one of the conditions is met.
+func Any(conditions ...Condition) Condition {
+	return func(s Status) bool {
+		for _, c := range conditions {
+			if c(s) {
+				return true
+			}
+		}
+		return false
+	}
+}
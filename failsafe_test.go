@@ -0,0 +1,145 @@
+package minidrone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFailsafe(t *testing.T) {
+	tests := []struct {
+		name string
+		rule FailsafeRule
+		// trigger makes the drone meet the condition of the rule
+		trigger func(ft *fakeTransport)
+		ticks   int
+		land    bool
+	}{
+		{
+			name: "battery",
+			rule: FailsafeRule{Name: "battery", Condition: BatteryBelow(20), Action: FailsafeLand},
+			trigger: func(ft *fakeTransport) {
+				ft.notify(projectCommon, classCommonState, cmdBatteryStateChanged, 15)
+			},
+			ticks: 1,
+			land:  true,
+		},
+		{
+			name: "link loss",
+			rule: FailsafeRule{Name: "link", Condition: LinkErrorsAbove(2), Action: FailsafeHover},
+			trigger: func(ft *fakeTransport) {
+				ft.mu.Lock()
+				ft.pcmdErr = errors.New("link lost")
+				ft.mu.Unlock()
+			},
+			ticks: 3,
+		},
+		{
+			name: "altitude",
+			rule: FailsafeRule{Name: "altitude", Condition: AltitudeAbove(2000), Action: FailsafeHover},
+			trigger: func(ft *fakeTransport) {
+				ft.notify(projectMinidrone, classNavigationDataState, cmdDroneAltitude, 0xc4, 0x09, 0x00, 0x00)
+			},
+			ticks: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fired []string
+			m, ft := startFake(t, WithPcmdErrorHandler(func(err error, consecutive int) {}))
+			m.OnEvent(func(event string, data interface{}) {
+				if event == Failsafe {
+					fired = append(fired, data.(FailsafeRule).Name)
+				}
+			})
+			m.SetFailsafe(tt.rule)
+			m.Forward(50)
+
+			m.Tick()
+			if len(fired) != 0 {
+				t.Fatalf("rule fired before its condition was met: %v", fired)
+			}
+
+			tt.trigger(ft)
+			for i := 0; i < tt.ticks; i++ {
+				m.Tick()
+			}
+			if len(fired) != 1 {
+				t.Fatalf("rule fired %d times, want 1", len(fired))
+			}
+
+			m.pcmdMutex.Lock()
+			pcmd := m.Pcmd
+			m.pcmdMutex.Unlock()
+			if pcmd != (Pcmd{}) {
+				t.Errorf("Pcmd = %+v after failsafe, want hover", pcmd)
+			}
+			if landed := ft.count(projectMinidrone, 0x00, 0x03) == 1; landed != tt.land {
+				t.Errorf("land sent = %v, want %v", landed, tt.land)
+			}
+
+			// the condition is still met, but the rule does not fire again
+			for i := 0; i < 3; i++ {
+				m.Tick()
+			}
+			if len(fired) != 1 {
+				t.Errorf("rule fired %d times while its condition stayed met, want 1", len(fired))
+			}
+			if tt.land && ft.count(projectMinidrone, 0x00, 0x03) != 1 {
+				t.Error("land sent again while the condition stayed met")
+			}
+		})
+	}
+}
+
+func TestFailsafeOrder(t *testing.T) {
+	var fired []string
+	m, ft := startFake(t, WithClassroomMode())
+	m.OnEvent(func(event string, data interface{}) {
+		if event == Failsafe {
+			fired = append(fired, data.(FailsafeRule).Name)
+		}
+	})
+	m.SetFailsafe(
+		FailsafeRule{Name: "first", Condition: BatteryBelow(50)},
+		FailsafeRule{Name: "second", Condition: BatteryBelow(50)},
+	)
+
+	ft.notify(projectMinidrone, classPilotingState, cmdFlyingStateChanged, byte(FlyingStateHovering), 0, 0, 0)
+	ft.notify(projectCommon, classCommonState, cmdBatteryStateChanged, 5)
+	m.Tick()
+
+	// the rules from options come before the ones set with SetFailsafe
+	want := []string{"classroom low battery", "first", "second"}
+	if len(fired) != len(want) {
+		t.Fatalf("fired %v, want %v", fired, want)
+	}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Errorf("fired %v, want %v", fired, want)
+			break
+		}
+	}
+}
+
+func TestFailsafeBypassesSlew(t *testing.T) {
+	m, ft := startFake(t, WithSlewRate(10))
+	m.SetFailsafe(FailsafeRule{Name: "battery", Condition: BatteryBelow(20), Action: FailsafeHover})
+
+	// the drone has already ramped up to a full forward pitch
+	m.pcmdMutex.Lock()
+	m.slewed = Pcmd{Pitch: 100}
+	m.pcmdMutex.Unlock()
+	m.Forward(100)
+
+	ft.notify(projectCommon, classCommonState, cmdBatteryStateChanged, 15)
+	m.Tick()
+	m.Tick()
+
+	ft.mu.Lock()
+	last := ft.pcmds[len(ft.pcmds)-1]
+	ft.mu.Unlock()
+	if pitch := int8(last[8]); pitch != 0 {
+		t.Errorf("pitch = %d after failsafe hover, want 0", pitch)
+	}
+}
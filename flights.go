@@ -0,0 +1,77 @@
+package minidrone
+
+import "time"
+
+// FlightStats are the cumulative flights and airtime of a drone.
+type FlightStats struct {
+	Flights int
+	Airtime time.Duration
+}
+
+// WithFlightStats seeds the flight counter with stats persisted from
+// earlier sessions with the same drone, so that they survive restarts.
+func WithFlightStats(stats FlightStats) Option {
+	return func(m *Minidrone) {
+		m.flights = stats
+	}
+}
+
+// WithMaintenanceReminder publishes a Maintenance event with the current
+// FlightStats every interval flights, as a reminder to check the props and
+// gears.
+func WithMaintenanceReminder(interval int) Option {
+	return func(m *Minidrone) {
+		m.maintenanceInterval = interval
+	}
+}
+
+// FlightStats returns the cumulative flights and airtime of the drone,
+// including the flight in progress.
+func (m *Minidrone) FlightStats() FlightStats {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.flightStats()
+}
+
+// flightStats must be called with the stateMutex held.
+func (m *Minidrone) flightStats() FlightStats {
+	stats := m.flights
+	if !m.flightStart.IsZero() {
+		stats.Airtime += time.Since(m.flightStart)
+	}
+
+	return stats
+}
+
+func (m *Minidrone) startFlight() {
+	m.stateMutex.Lock()
+	if !m.flightStart.IsZero() {
+		m.stateMutex.Unlock()
+		return
+	}
+	m.flightStart = time.Now()
+	m.flightTimeExceeded = false
+	m.flights.Flights++
+	stats := m.flights
+	due := m.maintenanceInterval > 0 && stats.Flights%m.maintenanceInterval == 0
+	m.stateMutex.Unlock()
+
+	if due {
+		m.publish(Maintenance, stats)
+	}
+}
+
+func (m *Minidrone) endFlight() {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	if m.flightStart.IsZero() {
+		return
+	}
+	m.flights.Airtime += time.Since(m.flightStart)
+	m.flightStart = time.Time{}
+
+	// every flight has to be armed again
+	m.armed = false
+}
@@ -0,0 +1,48 @@
+package minidrone
+
+// maxHeadingCorrection is the most yaw, in percent, that heading hold adds
+// to correct the heading.
+const maxHeadingCorrection = 30
+
+// WithHeadingHold keeps the heading of the drone while it is flying with
+// no yaw command, correcting the drift of these small drones with gain
+// percent of yaw per degree off the heading. The heading to hold is taken
+// whenever the yaw is released. It requires a firmware that sends position
+// notifications, and does nothing until one is received.
+func WithHeadingHold(gain int) Option {
+	return func(m *Minidrone) {
+		m.headingGain = gain
+	}
+}
+
+// holdHeading returns the yaw to send, with any heading correction. It must
+// be called with the pcmdMutex held.
+func (m *Minidrone) holdHeading(yaw int) int {
+	if m.headingGain <= 0 {
+		return yaw
+	}
+
+	m.stateMutex.Lock()
+	psi, known, turning := int(m.telemetry.Position.Psi), m.positionKnown, m.turnPending
+	m.stateMutex.Unlock()
+
+	if yaw != 0 || turning || !known || !m.IsFlying() {
+		m.headingHeld = false
+		return yaw
+	}
+
+	if !m.headingHeld {
+		m.heldHeading = psi
+		m.headingHeld = true
+	}
+
+	correction := m.headingGain * normalizeHeading(m.heldHeading-psi)
+	switch {
+	case correction > maxHeadingCorrection:
+		correction = maxHeadingCorrection
+	case correction < -maxHeadingCorrection:
+		correction = -maxHeadingCorrection
+	}
+
+	return correction
+}
@@ -0,0 +1,109 @@
+package minidrone
+
+import "time"
+
+// PcmdCommand is the name passed to a CommandHook for every pcmd frame.
+const PcmdCommand = "pcmd"
+
+// CommandHook observes every command written to the drone, so that metrics,
+// audit logging or recording can be added without instrumenting the driver.
+type CommandHook interface {
+	// OnCommandSent is called after cmd has been written to the drone,
+	// with how long the write took and the error it returned, if any.
+	OnCommandSent(cmd string, latency time.Duration, err error)
+}
+
+// CommandHookFunc is a function that can be used as a CommandHook.
+type CommandHookFunc func(cmd string, latency time.Duration, err error)
+
+// OnCommandSent calls f(cmd, latency, err).
+func (f CommandHookFunc) OnCommandSent(cmd string, latency time.Duration, err error) {
+	f(cmd, latency, err)
+}
+
+// WithCommandHook adds a CommandHook. It can be used more than once to
+// add several hooks, which are called in order.
+func WithCommandHook(hook CommandHook) Option {
+	return func(m *Minidrone) {
+		m.commandHooks = append(m.commandHooks, hook)
+	}
+}
+
+// writeCommand writes buf to the command characteristic and reports it to
+// the command hooks as cmd. Commands are written one at a time, in the order
+// they are sent, and get the next fa0b sequence number in buf[1].
+func (m *Minidrone) writeCommand(cmd string, buf []byte) (err error) {
+	m.commandMutex.Lock()
+	defer m.commandMutex.Unlock()
+
+	if !m.queueCommand(cmd, buf) {
+		return nil
+	}
+
+	m.stepsfa0b++
+	buf[1] = m.stepsfa0b
+
+	start := time.Now()
+	if m.ackRetries >= 0 {
+		err = m.writeAcked(buf)
+	} else {
+		err = m.sendCommand(buf)
+	}
+	m.commandWritten(cmd, buf)
+	m.commandSent(cmd, buf, time.Since(start), err)
+
+	return err
+}
+
+// writePriority writes buf to the priority characteristic and reports it to
+// the command hooks as cmd, with the next fa0c sequence number in buf[1].
+func (m *Minidrone) writePriority(cmd string, buf []byte) error {
+	m.priorityMutex.Lock()
+	defer m.priorityMutex.Unlock()
+
+	m.stepsfa0c++
+	buf[1] = m.stepsfa0c
+
+	start := time.Now()
+	err := m.sendPriority(buf)
+	m.commandSent(cmd, buf, time.Since(start), err)
+
+	return err
+}
+
+// writePcmd writes the current pcmd frame to the pcmd characteristic.
+func (m *Minidrone) writePcmd() error {
+	start := time.Now()
+	err := m.sendPcmd(m.pcmddata)
+	m.commandSent(PcmdCommand, m.pcmddata, time.Since(start), err)
+
+	return err
+}
+
+func (m *Minidrone) commandSent(cmd string, buf []byte, latency time.Duration, err error) {
+	if m.recorder != nil {
+		m.recorder.recordCommand(cmd, buf, err)
+	}
+
+	for _, hook := range m.commandHooks {
+		hook.OnCommandSent(cmd, latency, err)
+	}
+}
+
+// flushRecorder writes the records buffered by the recorder, if any.
+func (m *Minidrone) flushRecorder() {
+	if m.recorder != nil {
+		m.recorder.flush()
+	}
+}
+
+// recorder records the traffic with the drone, see WithRecorder.
+type recorder interface {
+	recordCommand(cmd string, frame []byte, err error)
+	recordNotification(ch Channel, frame []byte)
+	recordEvent(event string, data interface{})
+
+	// flush writes the records buffered so far. It is called without any of
+	// the driver locks held.
+	flush()
+}
@@ -0,0 +1,102 @@
+// Package hud draws the status of a Minidrone on a small display, such as
+// the screen of a hardware remote: connection and flying state, battery,
+// stick positions and buttons.
+package hud
+
+import (
+	"image/color"
+	"strconv"
+
+	minidrone "github.com/hybridgroup/tinygo-minidrone"
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/tinydraw"
+	"tinygo.org/x/tinyfont"
+	"tinygo.org/x/tinyfont/proggy"
+)
+
+// buttonRadius is the radius of the button indicators, in pixels.
+const buttonRadius = 4
+
+// Position is the position of a stick, in percent from -100 to 100.
+type Position struct {
+	X, Y int
+}
+
+// State is what the HUD shows.
+type State struct {
+	// Connected is false while the remote is looking for the drone.
+	Connected bool
+
+	// Drone is the status of the drone, from Minidrone.Status.
+	Drone minidrone.Status
+
+	// Left and Right are the positions of the sticks.
+	Left, Right Position
+
+	// Buttons are shown as circles along the bottom of the display, filled
+	// while pressed.
+	Buttons []bool
+}
+
+// HUD draws a State on a display.
+type HUD struct {
+	Display drivers.Displayer
+
+	// Font and LineHeight are used for the lines of text.
+	Font       tinyfont.Fonter
+	LineHeight int16
+
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+// New returns a HUD for the display, with a small font that fits a 128x64
+// screen.
+func New(display drivers.Displayer) *HUD {
+	return &HUD{
+		Display:    display,
+		Font:       &proggy.TinySZ8pt7b,
+		LineHeight: 12,
+		Foreground: color.RGBA{255, 255, 255, 255},
+		Background: color.RGBA{0, 0, 0, 255},
+	}
+}
+
+// Show draws s and sends it to the display.
+func (h *HUD) Show(s State) error {
+	w, ht := h.Display.Size()
+	tinydraw.FilledRectangle(h.Display, 0, 0, w, ht, h.Background)
+
+	if !s.Connected {
+		h.line(0, "connecting")
+		return h.Display.Display()
+	}
+
+	battery := "--"
+	if s.Drone.Battery >= 0 {
+		battery = strconv.Itoa(s.Drone.Battery) + "%"
+	}
+	h.line(0, s.Drone.FlyingState.String()+" "+battery)
+	h.line(1, "L "+position(s.Left)+" R "+position(s.Right))
+
+	for i, pressed := range s.Buttons {
+		x := int16(16 + 32*i)
+		y := ht - buttonRadius - 1
+		if pressed {
+			tinydraw.FilledCircle(h.Display, x, y, buttonRadius, h.Foreground)
+		} else {
+			tinydraw.Circle(h.Display, x, y, buttonRadius, h.Foreground)
+		}
+	}
+
+	return h.Display.Display()
+}
+
+// line writes the nth line of text.
+func (h *HUD) line(n int16, text string) {
+	tinyfont.WriteLine(h.Display, h.Font, 2, h.LineHeight*(n+1), text, h.Foreground)
+}
+
+func position(p Position) string {
+	return strconv.Itoa(p.X) + "," + strconv.Itoa(p.Y)
+}
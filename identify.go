@@ -0,0 +1,61 @@
+package minidrone
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCannotIdentify is returned by Identify when the drone has no lights to
+// blink and is not hovering, so it has no way to show itself.
+var ErrCannotIdentify = errors.New("drone has no lights and is not hovering")
+
+// Identify helps to physically pick out this drone among several. Drones
+// with lights blink them for a few seconds, then go back to the light mode
+// they were in. Drones without lights cannot tell us so, so the drone also
+// wiggles gently left and right if it is hovering, then goes back to the
+// Pcmd it had. A drone that is flying around is not wiggled.
+func (m *Minidrone) Identify() error {
+	// a drone that was renamed may still have lights
+	caps, ok := m.Capabilities()
+	lights := !ok || caps.Headlights
+	hovering := m.FlyingState() == FlyingStateHovering
+	if !lights && !hovering {
+		return ErrCannotIdentify
+	}
+
+	m.stateMutex.Lock()
+	mode, intensity := m.lightMode, m.lightIntensity
+	m.stateMutex.Unlock()
+
+	if lights {
+		err := m.LightControl(0, LightBlinked, 100)
+		if err != nil {
+			return err
+		}
+	}
+
+	if hovering {
+		m.pcmdMutex.Lock()
+		pcmd := m.Pcmd
+		m.pcmdMutex.Unlock()
+
+		for i := 0; i < 3; i++ {
+			m.Clockwise(20)
+			time.Sleep(200 * time.Millisecond)
+			m.CounterClockwise(20)
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		m.pcmdMutex.Lock()
+		m.Pcmd = pcmd
+		m.pcmdMutex.Unlock()
+	} else {
+		time.Sleep(2 * time.Second)
+	}
+
+	if !lights {
+		return nil
+	}
+
+	return m.LightControl(0, mode, intensity)
+}
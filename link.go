@@ -0,0 +1,133 @@
+package minidrone
+
+import "time"
+
+// WithLinkMonitor publishes a LinkLost event when no pcmd could be written
+// or no notification was received from the drone for longer than timeout,
+// and a LinkRestored event once both work again. When hover is true, the
+// drone is also told to hover when the link is lost, so that it stops
+// rather than carrying on with its last movement.
+//
+// The drone does not send notifications at a fixed rate, so use a timeout
+// of a few seconds, or WithStateRefresh to make it report regularly.
+func WithLinkMonitor(timeout time.Duration, hover bool) Option {
+	return func(m *Minidrone) {
+		m.linkTimeout = timeout
+		m.linkHover = hover
+	}
+}
+
+// WithPcmdErrorHandler calls handler when a pcmd cannot be written, with the
+// error and the number of consecutive failed writes, instead of printing
+// the error.
+func WithPcmdErrorHandler(handler func(err error, consecutive int)) Option {
+	return func(m *Minidrone) {
+		m.pcmdErrorHandler = handler
+	}
+}
+
+// WithPcmdErrorLimit publishes a LinkLost event once count pcmd writes in a
+// row have failed, and LinkRestored after the next successful write.
+func WithPcmdErrorLimit(count int) Option {
+	return func(m *Minidrone) {
+		m.pcmdErrorLimit = count
+	}
+}
+
+// LinkStatus is the data of the LinkLost and LinkRestored events.
+type LinkStatus struct {
+	// SincePcmd is the time since the last successful pcmd write.
+	SincePcmd time.Duration
+
+	// SinceNotification is the time since the last notification received.
+	SinceNotification time.Duration
+
+	// PcmdErrors is the number of consecutive failed pcmd writes.
+	PcmdErrors int
+}
+
+// resetLink restarts the link monitor, for a new connection.
+func (m *Minidrone) resetLink() {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	now := time.Now()
+	m.lastPcmdWrite = now
+	m.lastNotification = now
+	m.linkLost = false
+	m.pcmdErrors = 0
+}
+
+// pcmdWritten records that the link was able to carry a pcmd, or that no
+// pcmd was due.
+func (m *Minidrone) pcmdWritten() {
+	m.stateMutex.Lock()
+	m.lastPcmdWrite = time.Now()
+	m.pcmdErrors = 0
+	m.stateMutex.Unlock()
+}
+
+// pcmdFailed counts a failed pcmd write and reports it.
+func (m *Minidrone) pcmdFailed(err error) {
+	m.stateMutex.Lock()
+	m.linkErrors++
+	m.pcmdErrors++
+	consecutive := m.pcmdErrors
+	m.stateMutex.Unlock()
+
+	if m.pcmdErrorHandler != nil {
+		m.pcmdErrorHandler(err, consecutive)
+		return
+	}
+	println("pcmd write error:", err.Error())
+}
+
+func (m *Minidrone) notificationReceived() {
+	if m.linkTimeout <= 0 {
+		return
+	}
+
+	m.stateMutex.Lock()
+	m.lastNotification = time.Now()
+	m.stateMutex.Unlock()
+}
+
+// checkLink publishes LinkLost or LinkRestored when the state of the link
+// changes.
+func (m *Minidrone) checkLink() {
+	if m.linkTimeout <= 0 && m.pcmdErrorLimit <= 0 {
+		return
+	}
+
+	now := time.Now()
+	m.stateMutex.Lock()
+	status := LinkStatus{
+		SincePcmd:         now.Sub(m.lastPcmdWrite),
+		SinceNotification: now.Sub(m.lastNotification),
+		PcmdErrors:        m.pcmdErrors,
+	}
+	lost := m.linkTimeout > 0 && (status.SincePcmd > m.linkTimeout || status.SinceNotification > m.linkTimeout)
+	if m.pcmdErrorLimit > 0 && status.PcmdErrors >= m.pcmdErrorLimit {
+		lost = true
+	}
+	changed := lost != m.linkLost
+	m.linkLost = lost
+	m.stateMutex.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if !lost {
+		m.publish(LinkRestored, status)
+		return
+	}
+
+	if debug {
+		println("link lost")
+	}
+	m.publish(LinkLost, status)
+	if m.linkHover {
+		m.hoverNow()
+	}
+}
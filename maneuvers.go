@@ -0,0 +1,71 @@
+package minidrone
+
+import (
+	"context"
+	"time"
+)
+
+// MoveFor moves the drone as with Move for the duration and then hovers.
+// The movement is sent again every PcmdInterval so that a watchdog does not
+// stop it. When ctx is done first, the drone hovers and the context error is
+// returned.
+func (m *Minidrone) MoveFor(ctx context.Context, roll, pitch, yaw, gaz int, duration time.Duration) error {
+	defer m.Hover()
+
+	done := time.NewTimer(duration)
+	defer done.Stop()
+	tick := time.NewTicker(PcmdInterval)
+	defer tick.Stop()
+
+	for {
+		err := m.Move(roll, pitch, yaw, gaz)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-tick.C:
+		case <-done.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// FlySquare flies forward, right, backward and left for sideDuration each
+// at speed from 0 to 100, without turning, ending where it started.
+func (m *Minidrone) FlySquare(ctx context.Context, sideDuration time.Duration, speed int) error {
+	sides := [4][2]int{
+		{0, speed},  // forward
+		{speed, 0},  // right
+		{0, -speed}, // backward
+		{-speed, 0}, // left
+	}
+
+	for _, side := range sides {
+		err := m.MoveFor(ctx, side[0], side[1], 0, 0, sideDuration)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FlyCircle flies forward at pitch while turning at yawRate, both from -100
+// to 100, for the duration. A positive yawRate circles clockwise.
+func (m *Minidrone) FlyCircle(ctx context.Context, yawRate, pitch int, duration time.Duration) error {
+	return m.MoveFor(ctx, 0, pitch, yawRate, 0, duration)
+}
+
+// FigureEight flies one circle clockwise and one counter-clockwise, each
+// taking loopDuration, as with FlyCircle.
+func (m *Minidrone) FigureEight(ctx context.Context, yawRate, pitch int, loopDuration time.Duration) error {
+	err := m.FlyCircle(ctx, yawRate, pitch, loopDuration)
+	if err != nil {
+		return err
+	}
+
+	return m.FlyCircle(ctx, -yawRate, pitch, loopDuration)
+}
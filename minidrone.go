@@ -1,65 +1,142 @@
 package minidrone
 
 import (
-	"encoding/binary"
-	"errors"
-	"fmt"
+	"context"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"tinygo.org/x/bluetooth"
 )
 
 type Minidrone struct {
-	device                     *bluetooth.Device
-	commandService             *bluetooth.DeviceService
-	commandCharacteristic      *bluetooth.DeviceCharacteristic
-	pcmdCharacteristic         *bluetooth.DeviceCharacteristic
-	notificationService        *bluetooth.DeviceService
-	flightStatusCharacteristic *bluetooth.DeviceCharacteristic
-
-	buf       []byte
-	stepsfa0a uint16
-	stepsfa0b uint16
-	pcmdMutex sync.Mutex
-	Flying    bool
-	Pcmd      Pcmd
-	pcmddata  []byte
-	shutdown  chan bool
+	transport Transport
+
+	// sequence numbers of the frames sent on fa0a, fa0b and fa0c, which
+	// wrap around after 255 like the drone expects. They are guarded by
+	// the pcmdMutex, the commandMutex and the priorityMutex.
+	stepsfa0a     uint8
+	stepsfa0b     uint8
+	stepsfa0c     uint8
+	pcmdMutex     sync.Mutex
+	priorityMutex sync.Mutex
+
+	// Deprecated: Flying is written from the notification goroutine without
+	// synchronization. Use IsFlying instead.
+	Flying bool
+
+	Pcmd     Pcmd
+	pcmddata []byte
+	lastPcmd Pcmd
+	slewed   Pcmd
+	lastSlew time.Time
+	slewRate int
+	shutdown chan bool
+
+	manualTick      bool
+	priorityLanding bool
+	autoFlatTrim    bool
+	lowLatency      bool
+	pcmdPaused      bool
+	skipIdlePcmd    bool
+	takeoffProfile  TakeoffProfile
+	takeoffRamp     time.Time
+	lastMove        time.Time
+	watchdog        time.Duration
+	speedLimit      int
+	maxAltitude     float32
+	noFlips         bool
+	armingRequired  bool
+	armed           bool
+	maxFlightTime   time.Duration
+
+	// flying and flyingState are accessed atomically
+	flying      int32
+	flyingState int32
+
+	stateMutex sync.Mutex
+
+	// stateChanged is closed and replaced whenever the flying state changes
+	stateChanged chan struct{}
+
+	// flatTrimmed is closed and replaced whenever the drone confirms a flat trim
+	flatTrimmed chan struct{}
+
+	battery           int
+	batteryThresholds []*batteryThreshold
+	rssi              int16
+	linkErrors        int
+	flights           FlightStats
+	telemetry         Telemetry
+	product           ProductInfo
+	calibration       Calibration
+	accessory         Accessory
+	lightMode         uint8
+	lightIntensity    uint8
+	charge            ChargeState
+	modes             PilotingModes
+	pilotingSettings  PilotingSettings
+	speedSettings     SpeedSettings
+	turnTarget        int
+	turnPending       bool
+	turnDeadline      time.Time
+	positionKnown     bool
+	headingGain       int
+	heldHeading       int
+	headingHeld       bool
+	flightStart       time.Time
+
+	flightTimeExceeded bool
+
+	linkTimeout      time.Duration
+	linkHover        bool
+	linkLost         bool
+	lastPcmdWrite    time.Time
+	lastNotification time.Time
+	pcmdErrors       int
+	pcmdErrorLimit   int
+	pcmdErrorHandler func(err error, consecutive int)
+
+	maintenanceInterval int
+	commandHooks        []CommandHook
+	recorder            recorder
+	trace               TraceFunc
+	refreshInterval     time.Duration
+	lastRefresh         time.Time
+	refreshing          int32
+	refreshPending      bool
+	landPending         bool
+
+	commandMutex    sync.Mutex
+	commandSpacing  time.Duration
+	lastCommand     time.Time
+	lastCommandName string
+	lastCommandArgs []byte
+	ackRetries      int
+	ackTimeout      time.Duration
+	acks            chan byte
+
+	presetRules    []FailsafeRule
+	userRules      []FailsafeRule
+	failsafeRules  []FailsafeRule
+	failsafeActive []bool
 
 	pilotingStateHandler func(state, substate int)
-}
+	pilotingEventHandler func(event PilotingEvent, state FlyingState)
+	eventHandler         func(event string, data interface{})
 
-var (
-	// BLE services
-	droneCommandServiceUUID      = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x00, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
-	droneNotificationServiceUUID = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfb, 0x00, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+	eventMutex   sync.Mutex
+	eventWaiters []*eventWaiter
+}
 
-	// send characteristics
-	pcmdCharacteristicUUID     = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x0a, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
-	commandCharacteristicUUID  = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x0b, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
-	priorityCharacteristicUUID = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfa, 0x0c, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
+// defaultDate is sent to the drone when the current date is not known.
+var defaultDate = time.Date(2014, 10, 28, 0, 0, 0, 0, time.UTC)
 
-	// receive characteristics
-	flightStatusCharacteristicUUID = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfb, 0x0e, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
-	batteryCharacteristicUUID      = bluetooth.NewUUID([16]byte{0x9a, 0x66, 0xfb, 0x0f, 0x08, 0x00, 0x91, 0x91, 0x11, 0xe4, 0x01, 0x2d, 0x15, 0x40, 0xcb, 0x8e})
-)
+// PcmdInterval is how often the Pcmd is sent to the Minidrone.
+const PcmdInterval = 50 * time.Millisecond
 
 const (
-	// piloting states
-	PilotingStateFlatTrimChanged    = 0
-	PilotingStateFlyingStateChanged = 1
-
-	// flying states
-	FlyingStateLanded    = 0
-	FlyingStateTakeoff   = 1
-	FlyingStateHovering  = 2
-	FlyingStateFlying    = 3
-	FlyingStateLanding   = 4
-	FlyingStateEmergency = 5
-	FlyingStateRolling   = 6
-
 	// Battery event
 	Battery = "battery"
 
@@ -87,9 +164,88 @@ const (
 	// Rolling event
 	Rolling = "rolling"
 
+	// Failsafe event
+	Failsafe = "failsafe"
+
+	// AlertChange event
+	AlertChange = "alertchange"
+
+	// AutoTakeOffModeChange event
+	AutoTakeOffModeChange = "autotakeoffmodechange"
+
+	// FlyingModeChange event
+	FlyingModeChange = "flyingmodechange"
+
+	// PlaneGearChange event
+	PlaneGearChange = "planegearchange"
+
+	// LinkLost event
+	LinkLost = "linklost"
+
+	// LinkRestored event
+	LinkRestored = "linkrestored"
+
+	// Alert event, published with the AlertState when the drone raises a
+	// new alert
+	Alert = "alert"
+
+	// PositionChange event
+	PositionChange = "positionchange"
+
+	// SpeedChange event
+	SpeedChange = "speedchange"
+
+	// AltitudeChange event
+	AltitudeChange = "altitudechange"
+
+	// AttitudeChange event
+	AttitudeChange = "attitudechange"
+
+	// TurnComplete event
+	TurnComplete = "turncomplete"
+
+	// FlightTimeExceeded event
+	FlightTimeExceeded = "flighttimeexceeded"
+
+	// Maintenance event
+	Maintenance = "maintenance"
+
 	// FlatTrimChange event
 	FlatTrimChange = "flattrimchange"
 
+	// ProductChange event
+	ProductChange = "productchange"
+
+	// AllStatesReceived event
+	AllStatesReceived = "allstatesreceived"
+
+	// AllSettingsReceived event
+	AllSettingsReceived = "allsettingsreceived"
+
+	// CalibrationChange event
+	CalibrationChange = "calibrationchange"
+
+	// CalibrationRequired event
+	CalibrationRequired = "calibrationrequired"
+
+	// AccessoryChange event
+	AccessoryChange = "accessorychange"
+
+	// ChargeChange event
+	ChargeChange = "chargechange"
+
+	// MaxAltitudeChange event
+	MaxAltitudeChange = "maxaltitudechange"
+
+	// MaxTiltChange event
+	MaxTiltChange = "maxtiltchange"
+
+	// MaxVerticalSpeedChange event
+	MaxVerticalSpeedChange = "maxverticalspeedchange"
+
+	// MaxRotationSpeedChange event
+	MaxRotationSpeedChange = "maxrotationspeedchange"
+
 	// LightFixed mode for LightControl
 	LightFixed = 0
 
@@ -116,9 +272,17 @@ type Pcmd struct {
 	Psi   float32
 }
 
-func NewMinidrone(dev *bluetooth.Device) *Minidrone {
+// NewMinidrone returns a new Minidrone for the connected device, configured
+// with any of the given options.
+func NewMinidrone(dev *bluetooth.Device, opts ...Option) *Minidrone {
+	return NewMinidroneTransport(NewBLETransport(dev), opts...)
+}
+
+// NewMinidroneTransport returns a new Minidrone that talks to the drone
+// over the transport, configured with any of the given options.
+func NewMinidroneTransport(t Transport, opts ...Option) *Minidrone {
 	n := &Minidrone{
-		device: dev,
+		transport: t,
 		Pcmd: Pcmd{
 			Flag:  0,
 			Roll:  0,
@@ -127,71 +291,114 @@ func NewMinidrone(dev *bluetooth.Device) *Minidrone {
 			Gaz:   0,
 			Psi:   0,
 		},
-		pcmddata: make([]byte, 19),
+		pcmddata: make([]byte, pcmdLen),
 		shutdown: make(chan bool),
-		buf:      make([]byte, 255),
+		battery:  -1,
+
+		// the lights of the drones that have them are on when they start
+		lightMode:      LightFixed,
+		lightIntensity: 100,
+
+		ackRetries: -1,
+		acks:       make(chan byte, 8),
+
+		stateChanged: make(chan struct{}),
+		flatTrimmed:  make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(n)
 	}
 
 	return n
 }
 
+// PilotingStateChange sets the handler called when the drone confirms a flat
+// trim, with PilotingStateFlatTrimChanged, or changes its flying state, with
+// PilotingStateFlyingStateChanged and the flying state as substate.
+//
+// Deprecated: use OnPilotingEvent, which passes typed values.
 func (m *Minidrone) PilotingStateChange(handler func(state, substate int)) {
 	m.pilotingStateHandler = handler
 }
 
-func (m *Minidrone) Start() (err error) {
-	if debug {
-		println("drone: Start")
+// OnPilotingEvent sets the handler called when the drone confirms a flat trim
+// or changes its flying state, with the current flying state.
+func (m *Minidrone) OnPilotingEvent(handler func(event PilotingEvent, state FlyingState)) {
+	m.pilotingEventHandler = handler
+}
+
+// pilotingEvent calls the piloting handlers.
+func (m *Minidrone) pilotingEvent(event PilotingEvent, state FlyingState) {
+	if m.pilotingStateHandler != nil {
+		substate := int(state)
+		if event == PilotingEventFlatTrimChanged {
+			substate = 0
+		}
+		m.pilotingStateHandler(int(event), substate)
 	}
-	srvcs, err := m.device.DiscoverServices([]bluetooth.UUID{
-		droneCommandServiceUUID,
-		droneNotificationServiceUUID,
-	})
-	switch {
-	case err != nil:
-		return err
-	case len(srvcs) == 0:
-		return errors.New("could not find drone services")
+	if m.pilotingEventHandler != nil {
+		m.pilotingEventHandler(event, state)
 	}
+}
 
-	m.commandService = &srvcs[0]
-	m.notificationService = &srvcs[1]
-	if debug {
-		println("found drone command service", m.commandService.UUID().String())
-		println("found drone notify service", m.notificationService.UUID().String())
+// OnEvent sets the handler that is called for every event published by the
+// Minidrone, such as Battery or Failsafe.
+func (m *Minidrone) OnEvent(handler func(event string, data interface{})) {
+	m.eventHandler = handler
+}
+
+// listening reports whether published events are used at all. The
+// notification path checks it before publishing frequent events, so that
+// their data is not boxed into an interface, which allocates, when nobody
+// would receive it.
+func (m *Minidrone) listening() bool {
+	if m.eventHandler != nil || m.recorder != nil {
+		return true
 	}
 
-	chars, err := m.commandService.DiscoverCharacteristics([]bluetooth.UUID{
-		commandCharacteristicUUID,
-		pcmdCharacteristicUUID,
-	})
-	switch {
-	case err != nil:
-		return err
-	case len(chars) == 0:
-		return errors.New("could not find drone command characteristics")
+	m.eventMutex.Lock()
+	defer m.eventMutex.Unlock()
+
+	return len(m.eventWaiters) > 0
+}
+
+func (m *Minidrone) publish(event string, data interface{}) {
+	if m.recorder != nil {
+		m.recorder.recordEvent(event, data)
 	}
+	m.notifyWaiters(event, data)
+	if m.eventHandler != nil {
+		m.eventHandler(event, data)
+	}
+}
 
+// Start discovers the drone services and characteristics, initializes the
+// drone, and starts sending the Pcmd.
+func (m *Minidrone) Start() (err error) {
+	return m.StartContext(context.Background())
+}
+
+// StartContext is like Start, but gives up with the context error when ctx
+// is done before the drone has been initialized and has confirmed its flat
+// trim.
+func (m *Minidrone) StartContext(ctx context.Context) (err error) {
 	if debug {
-		println("found drone command characteristics", chars[0].UUID().String(), chars[1].UUID().String())
+		println("drone: Start")
 	}
-	m.commandCharacteristic = &chars[0]
-	m.pcmdCharacteristic = &chars[1]
 
-	chars, err = m.notificationService.DiscoverCharacteristics([]bluetooth.UUID{
-		flightStatusCharacteristicUUID,
-	})
-	switch {
-	case err != nil:
+	err = m.transport.Connect(ctx)
+	if err != nil {
 		return err
-	case len(chars) == 0:
-		return errors.New("could not find drone notify characteristics")
 	}
 
-	if debug {
-		println("found drone notify characteristics", chars[0].UUID().String())
+	if m.lowLatency {
+		m.requestLowLatency()
 	}
-	m.flightStatusCharacteristic = &chars[0]
+
+	// a new connection starts over from the first sequence number
+	m.ResetSequence()
+	m.resetLink()
 
 	err = m.Init()
 	if err != nil {
@@ -204,94 +411,266 @@ func (m *Minidrone) Start() (err error) {
 	if debug {
 		println("drone init complete")
 	}
-	m.FlatTrim()
-	m.StartPcmd()
-	m.FlatTrim()
 
-	return
+	if m.maxAltitude > 0 {
+		err = m.SetMaxAltitude(m.maxAltitude)
+		if err != nil {
+			return err
+		}
+	}
+
+	trimCtx, cancel := context.WithTimeout(ctx, flatTrimTimeout)
+	err = m.FlatTrimAndWait(trimCtx)
+	cancel()
+	if ctx.Err() != nil {
+		// the deadline of ctx itself has passed, not only the trim timeout
+		return ctx.Err()
+	}
+	if err != nil && err != context.DeadlineExceeded {
+		return err
+	}
+	if debug && err != nil {
+		println("flat trim not confirmed")
+	}
+
+	// Init has just requested all states and settings
+	m.lastRefresh = time.Now()
+
+	if !m.manualTick {
+		m.StartPcmd()
+	}
+
+	return nil
 }
 
 // Halt stops minidrone driver (void)
 func (m *Minidrone) Halt() (err error) {
 	m.Land()
+	m.stopPcmd()
+	m.flushRecorder()
 
-	m.shutdown <- true
-	time.Sleep(500 * time.Millisecond)
 	return
 }
 
-// Init initializes the BLE insterfaces used by the Minidrone
+// HaltAndWait lands the Minidrone and waits until it reports that it has
+// landed before stopping the driver. If ctx is done first, the context error
+// is returned and the driver keeps running, so that the caller can decide
+// what to do with a drone that is still in the air.
+func (m *Minidrone) HaltAndWait(ctx context.Context) (err error) {
+	err = m.Land()
+	if err != nil {
+		return err
+	}
+
+	err = m.waitFlyingState(ctx, FlyingStateLanded)
+	if err != nil {
+		return err
+	}
+
+	m.stopPcmd()
+	m.flushRecorder()
+	return nil
+}
+
+func (m *Minidrone) stopPcmd() {
+	if !m.manualTick {
+		m.shutdown <- true
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// ResetSequence restarts the sequence numbers of the frames sent to the
+// drone. Start calls it on every new connection, as the drone drops frames
+// whose sequence numbers continue from a previous connection.
+func (m *Minidrone) ResetSequence() {
+	m.pcmdMutex.Lock()
+	m.stepsfa0a = 0
+	m.pcmdMutex.Unlock()
+
+	m.commandMutex.Lock()
+	m.stepsfa0b = 0
+	m.commandMutex.Unlock()
+
+	m.priorityMutex.Lock()
+	m.stepsfa0c = 0
+	m.priorityMutex.Unlock()
+}
+
+// Init subscribes to the drone notifications and sends it the current date
+// and time.
 func (m *Minidrone) Init() (err error) {
 	if debug {
 		println("init")
 	}
+
+	// if you do not enable the notifications, then you cannot send commands
+	// to the drone.
+	err = m.transport.Subscribe(m.processFrame)
+	if err != nil {
+		return
+	}
+
 	err = m.GenerateAllStates()
 	if err != nil {
 		println(err.Error())
 		return
 	}
 
-	if debug {
-		println("enabling pcmd notifications")
+	// ask for the current settings and states now that the notifications
+	// they are reported on are enabled
+	err = m.RequestAllSettings()
+	if err != nil {
+		return
 	}
 
-	// if you do not enable these notifications, then you cannot send commands to the drone.
-	err = m.flightStatusCharacteristic.EnableNotifications(func(buf []byte) {
-		m.processFlightStatus(buf)
-	})
-
-	// TODO: subscribe to battery notifications
-
-	return
+	return m.RequestAllStates()
 }
 
 func (m *Minidrone) Disconnect() {
-	m.device.Disconnect()
+	m.transport.Disconnect()
 }
 
-// GenerateAllStates sets up all the default states aka settings on the drone
+// GenerateAllStates sets up all the default states aka settings on the drone,
+// starting with the current date and time. When the clock has not been set,
+// as on most microcontrollers, a fixed date is used instead.
 func (m *Minidrone) GenerateAllStates() (err error) {
-	m.stepsfa0b++
-	buf := []byte{0x04, byte(m.stepsfa0b) & 0xff, 0x00, 0x04, 0x01, 0x00, 0x32, 0x30, 0x31, 0x34, 0x2D, 0x31, 0x30, 0x2D, 0x32, 0x38, 0x00}
-	_, err = m.commandCharacteristic.WriteWithoutResponse(buf)
+	now := time.Now()
+	if now.Year() < 2015 {
+		now = defaultDate
+	}
+
+	return m.SetDateTime(now)
+}
+
+// SetDateTime sets the date and time on the drone, which it uses to
+// timestamp media files.
+func (m *Minidrone) SetDateTime(t time.Time) (err error) {
+	err = m.writeCommand("currentdate", m.generateString(0x01, t.Format("2006-01-02")))
+	if err != nil {
+		return err
+	}
+
+	return m.writeCommand("currenttime", m.generateString(0x02, t.Format("T150405-0700")))
+}
 
-	return err
+// generateString returns a Common class command with a single string argument.
+func (m *Minidrone) generateString(cmd byte, arg string) []byte {
+	buf := make([]byte, 0, 7+len(arg))
+	buf = append(buf, 0x04, 0x00, 0x00, 0x04, cmd, 0x00)
+	buf = append(buf, arg...)
+	return append(buf, 0x00)
 }
 
-// TakeOff tells the Minidrone to takeoff
+// RequestAllStates asks the Minidrone to send all of its current states,
+// such as the battery level, as notifications.
+func (m *Minidrone) RequestAllStates() (err error) {
+	buf := []byte{0x02, 0x00, 0x00, 0x04, 0x00, 0x00}
+	return m.writeCommand("requestallstates", buf)
+}
+
+// RequestAllSettings asks the Minidrone to send all of its current settings
+// as notifications.
+func (m *Minidrone) RequestAllSettings() (err error) {
+	buf := []byte{0x02, 0x00, 0x00, 0x02, 0x00, 0x00}
+	return m.writeCommand("requestallsettings", buf)
+}
+
+// TakeOff tells the Minidrone to takeoff. With WithAutoFlatTrim it flat trims
+// first, and returns an error if the drone does not confirm the trim.
 func (m *Minidrone) TakeOff() (err error) {
-	m.stepsfa0b++
-	buf := []byte{0x02, byte(m.stepsfa0b) & 0xff, 0x02, 0x00, 0x01, 0x00}
-	_, err = m.commandCharacteristic.WriteWithoutResponse(buf)
+	if !m.isArmed() {
+		return ErrNotArmed
+	}
+
+	if m.autoFlatTrim {
+		ctx, cancel := context.WithTimeout(context.Background(), flatTrimTimeout)
+		err = m.FlatTrimAndWait(ctx)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
 
-	return err
+	buf := []byte{0x02, 0x00, 0x02, 0x00, 0x01, 0x00}
+	return m.writeCommand("takeoff", buf)
+}
+
+// ThrownTakeOff gets the Minidrone ready to be thrown into the air: it enables
+// auto takeoff mode and then tells it to takeoff, so the motors spin up as
+// soon as the drone is tossed.
+func (m *Minidrone) ThrownTakeOff() (err error) {
+	if !m.isArmed() {
+		return ErrNotArmed
+	}
+
+	err = m.AutoTakeOffMode(true)
+	if err != nil {
+		return err
+	}
+
+	return m.TakeOff()
 }
 
 // Land tells the Minidrone to land
 func (m *Minidrone) Land() (err error) {
-	m.stepsfa0b++
-	buf := []byte{0x02, byte(m.stepsfa0b) & 0xff, 0x02, 0x00, 0x03, 0x00}
-	_, err = m.commandCharacteristic.WriteWithoutResponse(buf)
+	if m.priorityLanding {
+		buf := []byte{0x02, 0x00, 0x02, 0x00, 0x03, 0x00}
+		return m.writePriority("land", buf)
+	}
 
-	return err
+	buf := []byte{0x02, 0x00, 0x02, 0x00, 0x03, 0x00}
+	return m.writeCommand("land", buf)
 }
 
 // FlatTrim calibrates the Minidrone to use its current position as being level
 func (m *Minidrone) FlatTrim() (err error) {
-	m.stepsfa0b++
-	buf := []byte{0x02, byte(m.stepsfa0b) & 0xff, 0x02, 0x00, 0x00, 0x00}
-	_, err = m.commandCharacteristic.WriteWithoutResponse(buf)
-
-	return err
+	buf := []byte{0x02, 0x00, 0x02, 0x00, 0x00, 0x00}
+	return m.writeCommand("flattrim", buf)
 }
 
-// Emergency sets the Minidrone into emergency mode
+// Emergency sets the Minidrone into emergency mode. It is sent on the
+// priority channel, so that it is not stuck behind other commands.
 func (m *Minidrone) Emergency() (err error) {
-	m.stepsfa0b++
-	buf := []byte{0x02, byte(m.stepsfa0b) & 0xff, 0x02, 0x00, 0x04, 0x00}
-	_, err = m.commandCharacteristic.WriteWithoutResponse(buf)
+	buf := []byte{0x02, 0x00, 0x02, 0x00, 0x04, 0x00}
+	return m.writePriority("emergency", buf)
+}
+
+// SetMaxAltitude sets the maximum altitude the Minidrone may fly at, in meters.
+func (m *Minidrone) SetMaxAltitude(meters float32) (err error) {
+	buf := []byte{0x02, 0x00, 0x02, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	putFloat32(buf[6:], meters)
+	return m.writeCommand("maxaltitude", buf)
+}
+
+// AutoTakeOffMode enables or disables auto takeoff mode, in which the
+// Minidrone takes off by itself as soon as it is thrown or released.
+func (m *Minidrone) AutoTakeOffMode(enable bool) (err error) {
+	buf := []byte{0x02, 0x00, 0x02, 0x00, 0x05, 0x00, boolByte(enable)}
+	return m.writeCommand("autotakeoffmode", buf)
+}
 
-	return err
+// ToPlaneMode switches a Parrot Swing into plane mode, flying forward.
+// Flips are rejected while in plane mode, and the Swing loop animations are
+// not supported.
+func (m *Minidrone) ToPlaneMode() error {
+	return m.flyingMode("planemode", FlyingModePlaneForward)
+}
+
+// ToQuadMode switches a Parrot Swing back into quadricopter mode.
+func (m *Minidrone) ToQuadMode() error {
+	return m.flyingMode("quadmode", FlyingModeQuad)
+}
+
+func (m *Minidrone) flyingMode(cmd string, mode FlyingMode) error {
+	buf := []byte{0x02, 0x00, 0x02, 0x00, 0x06, 0x00, byte(mode), 0x00, 0x00, 0x00}
+	return m.writeCommand(cmd, buf)
+}
+
+// SetWheels tells the Minidrone whether the wheels accessory is attached,
+// so it can adjust its flight model accordingly.
+func (m *Minidrone) SetWheels(present bool) (err error) {
+	buf := []byte{0x02, 0x00, 0x02, 0x01, 0x02, 0x00, boolByte(present)}
+	return m.writeCommand("wheels", buf)
 }
 
 // StartPcmd starts the continuous Pcmd communication with the Minidrone
@@ -306,22 +685,76 @@ func (m *Minidrone) StartPcmd() {
 			default:
 			}
 
-			m.generatePcmd()
-			_, err := m.pcmdCharacteristic.WriteWithoutResponse(m.pcmddata)
-			if err != nil {
-				fmt.Println("pcmd write error:", err)
-			}
-			time.Sleep(50 * time.Millisecond)
+			m.Tick()
+			time.Sleep(PcmdInterval)
 		}
 	}()
 }
 
+// Tick sends the current Pcmd to the Minidrone and runs the periodic checks,
+// such as the failsafe rules. It is called every PcmdInterval by StartPcmd.
+// When using WithManualTick, call it from your own loop or timer instead.
+func (m *Minidrone) Tick() {
+	if m.pcmdIsPaused() {
+		m.pcmdWritten()
+		m.checkLink()
+		m.evaluateFailsafe()
+		m.checkFlightTime()
+		m.sendPending()
+		m.flushRecorder()
+		return
+	}
+
+	var err error
+	if !m.skipIdlePcmd || !m.pcmdIdle() {
+		m.generatePcmd()
+		err = m.writePcmd()
+		if err != nil {
+			m.pcmdFailed(err)
+		}
+	}
+	if err == nil {
+		m.pcmdWritten()
+	}
+
+	m.checkLink()
+	m.evaluateFailsafe()
+	m.checkFlightTime()
+	m.refreshStates()
+	m.sendPending()
+	m.flushRecorder()
+}
+
+// PausePcmd temporarily stops sending the Pcmd to the Minidrone, for example
+// to free up the BLE link for a long transfer. The failsafe rules keep being
+// evaluated while paused.
+func (m *Minidrone) PausePcmd() {
+	m.pcmdMutex.Lock()
+	m.pcmdPaused = true
+	m.pcmdMutex.Unlock()
+}
+
+// ResumePcmd resumes sending the Pcmd after PausePcmd.
+func (m *Minidrone) ResumePcmd() {
+	m.pcmdMutex.Lock()
+	m.pcmdPaused = false
+	m.pcmdMutex.Unlock()
+}
+
+func (m *Minidrone) pcmdIsPaused() bool {
+	m.pcmdMutex.Lock()
+	defer m.pcmdMutex.Unlock()
+
+	return m.pcmdPaused
+}
+
 // Up tells the drone to ascend. Pass in an int from 0-100.
 func (m *Minidrone) Up(val int) error {
 	m.pcmdMutex.Lock()
 	defer m.pcmdMutex.Unlock()
 
 	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
 	m.Pcmd.Gaz = validatePitch(val)
 	return nil
 }
@@ -332,6 +765,7 @@ func (m *Minidrone) Down(val int) error {
 	defer m.pcmdMutex.Unlock()
 
 	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
 	m.Pcmd.Gaz = validatePitch(val) * -1
 	return nil
 }
@@ -342,6 +776,7 @@ func (m *Minidrone) Forward(val int) error {
 	defer m.pcmdMutex.Unlock()
 
 	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
 	m.Pcmd.Pitch = validatePitch(val)
 	return nil
 }
@@ -352,6 +787,7 @@ func (m *Minidrone) Backward(val int) error {
 	defer m.pcmdMutex.Unlock()
 
 	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
 	m.Pcmd.Pitch = validatePitch(val) * -1
 	return nil
 }
@@ -362,6 +798,7 @@ func (m *Minidrone) Right(val int) error {
 	defer m.pcmdMutex.Unlock()
 
 	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
 	m.Pcmd.Roll = validatePitch(val)
 	return nil
 }
@@ -372,6 +809,7 @@ func (m *Minidrone) Left(val int) error {
 	defer m.pcmdMutex.Unlock()
 
 	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
 	m.Pcmd.Roll = validatePitch(val) * -1
 	return nil
 }
@@ -382,6 +820,7 @@ func (m *Minidrone) Clockwise(val int) error {
 	defer m.pcmdMutex.Unlock()
 
 	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
 	m.Pcmd.Yaw = validatePitch(val)
 	return nil
 }
@@ -393,10 +832,79 @@ func (m *Minidrone) CounterClockwise(val int) error {
 	defer m.pcmdMutex.Unlock()
 
 	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
 	m.Pcmd.Yaw = validatePitch(val) * -1
 	return nil
 }
 
+// Move sets all four axes at once, so that no pcmd frame is sent with only
+// some of them updated. Pass in ints from -100 to 100: positive values go
+// right, forward, clockwise and up, negative values the opposite way.
+func (m *Minidrone) Move(roll, pitch, yaw, gaz int) error {
+	m.pcmdMutex.Lock()
+	defer m.pcmdMutex.Unlock()
+
+	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
+	m.Pcmd.Roll = validateAxis(roll)
+	m.Pcmd.Pitch = validateAxis(pitch)
+	m.Pcmd.Yaw = validateAxis(yaw)
+	m.Pcmd.Gaz = validateAxis(gaz)
+	return nil
+}
+
+// SetRoll sets the roll axis, leaving the other axes unchanged.
+// Pass in an int from -100 to 100: positive values go right, negative
+// values go left.
+func (m *Minidrone) SetRoll(val int) error {
+	m.pcmdMutex.Lock()
+	defer m.pcmdMutex.Unlock()
+
+	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
+	m.Pcmd.Roll = validateAxis(val)
+	return nil
+}
+
+// SetPitch sets the pitch axis, leaving the other axes unchanged.
+// Pass in an int from -100 to 100: positive values go forward,
+// negative values go backward.
+func (m *Minidrone) SetPitch(val int) error {
+	m.pcmdMutex.Lock()
+	defer m.pcmdMutex.Unlock()
+
+	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
+	m.Pcmd.Pitch = validateAxis(val)
+	return nil
+}
+
+// SetYaw sets the yaw axis, leaving the other axes unchanged.
+// Pass in an int from -100 to 100: positive values turn clockwise,
+// negative values turn counter-clockwise.
+func (m *Minidrone) SetYaw(val int) error {
+	m.pcmdMutex.Lock()
+	defer m.pcmdMutex.Unlock()
+
+	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
+	m.Pcmd.Yaw = validateAxis(val)
+	return nil
+}
+
+// SetGaz sets the gaz axis, leaving the other axes unchanged.
+// Pass in an int from -100 to 100: positive values go up, negative
+// values go down.
+func (m *Minidrone) SetGaz(val int) error {
+	m.pcmdMutex.Lock()
+	defer m.pcmdMutex.Unlock()
+
+	m.Pcmd.Flag = 1
+	m.lastMove = time.Now()
+	m.Pcmd.Gaz = validateAxis(val)
+	return nil
+}
+
 // Hover tells the drone to stop moving in any direction and simply hover in place
 func (m *Minidrone) Hover() error {
 	m.pcmdMutex.Lock()
@@ -416,125 +924,178 @@ func (m *Minidrone) Hover() error {
 
 // FrontFlip tells the drone to perform a front flip
 func (m *Minidrone) FrontFlip() error {
-	_, err := m.commandCharacteristic.WriteWithoutResponse(m.generateAnimation(0))
-
-	return err
+	return m.flip(AnimationFrontFlip)
 }
 
 // BackFlip tells the drone to perform a backflip
 func (m *Minidrone) BackFlip() error {
-	_, err := m.commandCharacteristic.WriteWithoutResponse(m.generateAnimation(1))
-
-	return err
+	return m.flip(AnimationBackFlip)
 }
 
 // RightFlip tells the drone to perform a flip to the right
 func (m *Minidrone) RightFlip() error {
-	_, err := m.commandCharacteristic.WriteWithoutResponse(m.generateAnimation(2))
-
-	return err
+	return m.flip(AnimationRightFlip)
 }
 
 // LeftFlip tells the drone to perform a flip to the left
 func (m *Minidrone) LeftFlip() error {
-	_, err := m.commandCharacteristic.WriteWithoutResponse(m.generateAnimation(3))
+	return m.flip(AnimationLeftFlip)
+}
+
+// LightControl controls the lights on those Minidrone models which
+// have the correct hardware, such as the Maclane, Blaze, & Swat.
+// Params:
+//
+//	id - always 0
+//	mode - either LightFixed, LightBlinked, or LightOscillated
+//	intensity - Light intensity from 0 (OFF) to 100 (Max intensity).
+//	Only used in LightFixed mode.
+func (m *Minidrone) LightControl(id, mode, intensity uint8) (err error) {
+	m.stateMutex.Lock()
+	m.lightMode, m.lightIntensity = mode, intensity
+	m.stateMutex.Unlock()
+
+	buf := []byte{0x02, 0x00, 0x02, 0x10, 0x00, 0x00, id, mode, 0x00, 0x00, 0x00, intensity}
+	return m.writeCommand("lightcontrol", buf)
+}
+
+// Headlights sets the intensity of each headlight on those Minidrone models
+// which have them, such as the Maclane, Blaze, & Swat.
+// Params:
+//
+//	left - Left light intensity from 0 (OFF) to 255 (Max intensity).
+//	right - Right light intensity from 0 (OFF) to 255 (Max intensity).
+func (m *Minidrone) Headlights(left, right uint8) (err error) {
+	buf := []byte{0x02, 0x00, 0x00, 0x16, 0x00, 0x00, left, right}
+	return m.writeCommand("headlights", buf)
+}
 
-	return err
+// ClawControl controls the claw on the Parrot Mambo
+// Params:
+//
+//	id - always 0
+//	mode - either ClawOpen or ClawClosed
+func (m *Minidrone) ClawControl(id, mode uint8) (err error) {
+	buf := []byte{0x02, 0x00, 0x02, 0x10, 0x01, 0x00, id, mode, 0x00, 0x00, 0x00}
+	return m.writeCommand("clawcontrol", buf)
 }
 
-func (m *Minidrone) generateAnimation(anim int) []byte {
-	m.stepsfa0b++
-	return []byte{0x02, byte(m.stepsfa0b) & 0xff, 0x02, 0x04, 0x00, 0x00, byte(anim), 0x00, 0x00, 0x00}
+// GunControl fires the gun on the Parrot Mambo
+// Params:
+//
+//	id - always 0
+func (m *Minidrone) GunControl(id uint8) (err error) {
+	buf := []byte{0x02, 0x00, 0x02, 0x10, 0x02, 0x00, id, 0x00, 0x00, 0x00, 0x00}
+	return m.writeCommand("guncontrol", buf)
 }
 
-func FlyingState(state int) string {
-	switch state {
-	case FlyingStateLanded:
-		return Landed
-	case FlyingStateTakeoff:
-		return Takeoff
-	case FlyingStateHovering:
-		return Hovering
-	case FlyingStateFlying:
-		return Flying
-	case FlyingStateLanding:
-		return Landing
-	case FlyingStateEmergency:
-		return Emergency
-	case FlyingStateRolling:
-		return Rolling
+func (m *Minidrone) flip(anim Animation) error {
+	err := m.checkFlip()
+	if err != nil {
+		return err
 	}
 
-	return "unknown"
+	return m.writeCommand(anim.String(), m.generateAnimation(anim))
+}
+
+func (m *Minidrone) generateAnimation(anim Animation) []byte {
+	return []byte{0x02, 0x00, 0x02, 0x04, 0x00, 0x00, byte(anim), 0x00, 0x00, 0x00}
+}
+
+// pcmdIdle reports whether the drone is landed and both the current and the
+// last transmitted Pcmd are all zeros, so there is nothing worth sending.
+func (m *Minidrone) pcmdIdle() bool {
+	landed := m.FlyingState() == FlyingStateLanded
+
+	m.pcmdMutex.Lock()
+	defer m.pcmdMutex.Unlock()
+
+	return landed && m.Pcmd == Pcmd{} && m.lastPcmd == Pcmd{}
 }
 
 func (m *Minidrone) generatePcmd() {
 	m.pcmdMutex.Lock()
 	defer m.pcmdMutex.Unlock()
 
+	m.checkWatchdog()
+	pcmd := m.slewPcmd(m.Pcmd)
+	m.lastPcmd = pcmd
+	pcmd.Yaw = m.holdHeading(pcmd.Yaw)
+
+	pcmd.Roll = m.limitSpeed(pcmd.Roll)
+	pcmd.Pitch = m.limitSpeed(pcmd.Pitch)
+	pcmd.Yaw = m.limitSpeed(pcmd.Yaw)
+	pcmd.Gaz = m.limitSpeed(pcmd.Gaz + m.takeoffGaz())
+	pcmd.Clamp()
+
 	m.stepsfa0a++
-	m.pcmddata[0] = 0x02
-	m.pcmddata[1] = byte(m.stepsfa0a)
-	m.pcmddata[2] = 0x02
-	m.pcmddata[3] = 0x00
-	m.pcmddata[4] = 0x02
-	m.pcmddata[5] = 0x00
-	m.pcmddata[6] = byte(m.Pcmd.Flag)
-	m.pcmddata[7] = byte(m.Pcmd.Roll)
-	m.pcmddata[8] = byte(m.Pcmd.Pitch)
-	m.pcmddata[9] = byte(m.Pcmd.Yaw)
-	m.pcmddata[10] = byte(m.Pcmd.Gaz)
-	binary.LittleEndian.PutUint32(m.buf[11:], math.Float32bits(m.Pcmd.Psi))
-	m.pcmddata[15] = 0x00
-	m.pcmddata[16] = 0x00
-	m.pcmddata[17] = 0x00
-	m.pcmddata[18] = 0x00
+	pcmd.encode(m.pcmddata, m.stepsfa0a)
 
 	return
 }
 
-func (m *Minidrone) processFlightStatus(data []byte) {
-	if len(data) < 5 {
-		// ignore, just a sync
-		return
-	}
-
-	switch data[4] {
-	case PilotingStateFlatTrimChanged:
+func (m *Minidrone) processPilotingState(f frame) {
+	switch f.command {
+	case cmdFlatTrimChanged:
 		if debug {
 			println("flatTrimChanged")
 		}
 
-		if m.pilotingStateHandler != nil {
-			m.pilotingStateHandler(int(data[4]), 0)
+		m.stateMutex.Lock()
+		close(m.flatTrimmed)
+		m.flatTrimmed = make(chan struct{})
+		m.stateMutex.Unlock()
+
+		m.publish(FlatTrimChange, nil)
+		m.pilotingEvent(PilotingEventFlatTrimChanged, m.FlyingState())
+
+	case cmdFlyingStateChanged:
+		if !f.argsLen(4) {
+			return
+		}
+		state := FlyingState(f.uint32At(0))
+
+		previous := FlyingState(atomic.SwapInt32(&m.flyingState, int32(state)))
+
+		// waking the waiters allocates a new channel, which only happens a
+		// few times per flight: the drone repeats the same state far more
+		// often than it changes it
+		if state != previous {
+			m.stateMutex.Lock()
+			close(m.stateChanged)
+			m.stateChanged = make(chan struct{})
+			m.stateMutex.Unlock()
 		}
 
-	case PilotingStateFlyingStateChanged:
-		switch data[6] {
+		switch state {
 		case FlyingStateLanded:
-			if m.Flying {
-				m.Flying = false
+			m.cancelTurn()
+			if m.IsFlying() {
+				m.setFlying(false)
 				if debug {
 					println("flyingStateLanded")
 				}
 			}
+			m.endFlight()
 
 		case FlyingStateTakeoff:
 			if debug {
 				println("flyingStateTakeoff")
 			}
+			m.startTakeoffRamp()
+			m.startFlight()
 
 		case FlyingStateHovering:
-			if !m.Flying {
-				m.Flying = true
+			if !m.IsFlying() {
+				m.setFlying(true)
 				if debug {
 					println("flyingStateHovering")
 				}
 			}
 
 		case FlyingStateFlying:
-			if !m.Flying {
-				m.Flying = true
+			if !m.IsFlying() {
+				m.setFlying(true)
 				if debug {
 					println("flyingStateFlying")
 				}
@@ -557,9 +1118,29 @@ func (m *Minidrone) processFlightStatus(data []byte) {
 
 		}
 
-		if m.pilotingStateHandler != nil {
-			m.pilotingStateHandler(int(data[4]), int(data[6]))
+		m.pilotingEvent(PilotingEventFlyingStateChanged, state)
+
+	case cmdAlertStateChanged:
+		if !f.argsLen(4) {
+			return
+		}
+		alert := AlertState(f.uint32At(0))
+		if debug {
+			println("alertStateChanged", alert.String())
+		}
+
+		m.stateMutex.Lock()
+		previous := m.telemetry.Alert
+		m.telemetry.Alert = alert
+		m.stateMutex.Unlock()
+
+		m.publish(AlertChange, alert)
+		if alert != AlertNone && alert != previous {
+			m.publish(Alert, alert)
 		}
+
+	default:
+		m.processPilotingModes(f)
 	}
 }
 
@@ -577,6 +1158,24 @@ func ValidatePitch(data float64, offset float64) int {
 	return 0
 }
 
+func validateAxis(val int) int {
+	if val > 100 {
+		return 100
+	} else if val < -100 {
+		return -100
+	}
+
+	return val
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
 func validatePitch(val int) int {
 	if val > 100 {
 		return 100
@@ -0,0 +1,303 @@
+// Package mission runs a sequence of steps with a Minidrone, such as take
+// off, move forward for two seconds, turn around and land.
+package mission
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	minidrone "github.com/hybridgroup/tinygo-minidrone"
+)
+
+// DefaultTimeout is the timeout of the steps that do not have their own.
+const DefaultTimeout = 10 * time.Second
+
+// flipDuration is how long the drone takes to complete a flip.
+const flipDuration = 2 * time.Second
+
+// Step is one step of a Mission.
+type Step struct {
+	// Name describes the step in a StepError.
+	Name string
+
+	// Timeout is how long the step may take, or DefaultTimeout if zero.
+	Timeout time.Duration
+
+	// Run runs the step with the drone. It must return when ctx is done.
+	Run func(ctx context.Context, d *minidrone.Minidrone) error
+}
+
+// timeout returns the timeout of the step, or DefaultTimeout.
+func (s Step) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return DefaultTimeout
+	}
+
+	return s.Timeout
+}
+
+// StepError is returned by Mission.Run when a step fails.
+type StepError struct {
+	// Index is the index of the step in the mission.
+	Index int
+
+	// Name is the name of the step.
+	Name string
+
+	// Err is the error returned by the step.
+	Err error
+}
+
+func (e *StepError) Error() string {
+	return "mission step " + e.Name + ": " + e.Err.Error()
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// ErrAborted is the error of the step that was running when Mission.Abort
+// was called.
+var ErrAborted = errors.New("mission aborted")
+
+// HoldPolicy is what the drone does once a mission has been aborted.
+type HoldPolicy int
+
+const (
+	// HoldHover stops the drone where it is.
+	HoldHover HoldPolicy = iota
+
+	// HoldLand lands the drone where it is.
+	HoldLand
+
+	// HoldReturnHome runs the Home steps of the mission, then lands.
+	HoldReturnHome
+)
+
+// Mission is a sequence of steps that are run one after the other.
+type Mission struct {
+	Steps []Step
+
+	// LandOnAbort lands the drone when a step fails. Otherwise it hovers.
+	LandOnAbort bool
+
+	// Home are the steps that fly the drone back to where the mission
+	// started when it is aborted with HoldReturnHome, as minidrones have no
+	// GPS to find their way back by themselves. The drone lands where it is
+	// when there are none.
+	Home []Step
+
+	mutex   sync.Mutex
+	cancel  context.CancelFunc
+	aborted bool
+	policy  HoldPolicy
+}
+
+// New returns a new Mission with the steps.
+func New(steps ...Step) *Mission {
+	return &Mission{
+		Steps: steps,
+	}
+}
+
+// Run runs the steps in order. When a step fails or takes longer than its
+// timeout, the mission is aborted: the drone hovers, or lands when
+// LandOnAbort is set, and a StepError is returned.
+func (ms *Mission) Run(ctx context.Context, d *minidrone.Minidrone) error {
+	ms.mutex.Lock()
+	ms.aborted = false
+	ms.mutex.Unlock()
+
+	for i, step := range ms.Steps {
+		err := ms.runStep(ctx, d, step)
+
+		ms.mutex.Lock()
+		aborted, policy := ms.aborted, ms.policy
+		ms.mutex.Unlock()
+
+		if aborted {
+			ms.hold(d, policy)
+			return &StepError{Index: i, Name: step.Name, Err: ErrAborted}
+		}
+		if err != nil {
+			ms.abort(d)
+			return &StepError{Index: i, Name: step.Name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// Abort stops a running mission: the current step is cancelled, and Run
+// returns a StepError with ErrAborted once the drone has done what policy
+// says. It does nothing when the mission is not running.
+func (ms *Mission) Abort(policy HoldPolicy) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if ms.cancel == nil {
+		return
+	}
+	ms.aborted = true
+	ms.policy = policy
+	ms.cancel()
+}
+
+// runStep runs step with its timeout, so that Abort can cancel it.
+func (ms *Mission) runStep(ctx context.Context, d *minidrone.Minidrone, step Step) error {
+	stepCtx, cancel := context.WithTimeout(ctx, step.timeout())
+	defer cancel()
+
+	ms.mutex.Lock()
+	ms.cancel = cancel
+	ms.mutex.Unlock()
+
+	err := step.Run(stepCtx, d)
+
+	ms.mutex.Lock()
+	ms.cancel = nil
+	ms.mutex.Unlock()
+
+	return err
+}
+
+func (ms *Mission) abort(d *minidrone.Minidrone) {
+	if ms.LandOnAbort {
+		ms.hold(d, HoldLand)
+		return
+	}
+	ms.hold(d, HoldHover)
+}
+
+// hold stops the drone as policy says once the mission has stopped.
+func (ms *Mission) hold(d *minidrone.Minidrone, policy HoldPolicy) {
+	d.Hover()
+
+	switch policy {
+	case HoldLand:
+		d.Land()
+
+	case HoldReturnHome:
+		for _, step := range ms.Home {
+			ctx, cancel := context.WithTimeout(context.Background(), step.timeout())
+			err := step.Run(ctx, d)
+			cancel()
+
+			if err != nil {
+				d.Hover()
+				break
+			}
+		}
+		d.Land()
+	}
+}
+
+// TakeOff takes off and waits until the drone is hovering.
+func TakeOff() Step {
+	return Step{
+		Name: "takeoff",
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			return d.TakeOffAndWait(ctx)
+		},
+	}
+}
+
+// Land lands and waits until the drone has landed.
+func Land() Step {
+	return Step{
+		Name: "land",
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			return d.LandAndWait(ctx)
+		},
+	}
+}
+
+// Move moves the drone as with Minidrone.Move for the duration, then hovers.
+func Move(roll, pitch, yaw, gaz int, duration time.Duration) Step {
+	return Step{
+		Name:    "move",
+		Timeout: duration + time.Second,
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			return d.MoveFor(ctx, roll, pitch, yaw, gaz, duration)
+		},
+	}
+}
+
+// Hover stops the drone and waits for the duration.
+func Hover(duration time.Duration) Step {
+	return Step{
+		Name:    "hover",
+		Timeout: duration + time.Second,
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			err := d.Hover()
+			if err != nil {
+				return err
+			}
+
+			return wait(ctx, duration)
+		},
+	}
+}
+
+// Turn turns the drone by degrees and waits until it has turned.
+func Turn(degrees float32) Step {
+	return Step{
+		Name: "turn",
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			return d.TurnAndWait(ctx, degrees)
+		},
+	}
+}
+
+// Flip runs a flip, such as (*minidrone.Minidrone).FrontFlip, and waits for
+// the drone to complete it.
+func Flip(flip func(d *minidrone.Minidrone) error) Step {
+	return Step{
+		Name: "flip",
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			err := flip(d)
+			if err != nil {
+				return err
+			}
+
+			return wait(ctx, flipDuration)
+		},
+	}
+}
+
+// WaitForEvent waits until the drone publishes the event.
+func WaitForEvent(event string, timeout time.Duration) Step {
+	return Step{
+		Name:    "wait for " + event,
+		Timeout: timeout,
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			_, err := d.WaitEvent(ctx, event)
+			return err
+		},
+	}
+}
+
+// Wait waits for the duration, leaving the drone as it is.
+func Wait(duration time.Duration) Step {
+	return Step{
+		Name:    "wait",
+		Timeout: duration + time.Second,
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			return wait(ctx, duration)
+		},
+	}
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
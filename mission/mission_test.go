@@ -0,0 +1,140 @@
+package mission
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	minidrone "github.com/hybridgroup/tinygo-minidrone"
+)
+
+// fakeTransport is an in-memory minidrone.Transport that records the command
+// frames written to it.
+type fakeTransport struct {
+	mu       sync.Mutex
+	commands [][]byte
+}
+
+func (t *fakeTransport) Connect(ctx context.Context) error { return nil }
+
+func (t *fakeTransport) Subscribe(handler func(ch minidrone.Channel, frame []byte)) error {
+	return nil
+}
+
+func (t *fakeTransport) WriteCommand(frame []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.commands = append(t.commands, append([]byte(nil), frame...))
+	return nil
+}
+
+func (t *fakeTransport) WritePcmd(frame []byte) error     { return nil }
+func (t *fakeTransport) WritePriority(frame []byte) error { return nil }
+func (t *fakeTransport) Disconnect() error                { return nil }
+
+// blocking returns a step that closes started, then waits until it is
+// cancelled.
+func blocking(started chan struct{}) Step {
+	return Step{
+		Name:    "blocking",
+		Timeout: time.Minute,
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+}
+
+// landed reports whether a land command was written.
+func (t *fakeTransport) landed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, c := range t.commands {
+		if len(c) >= 6 && c[2] == 0x02 && c[3] == 0x00 && c[4] == 0x03 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestAbort(t *testing.T) {
+	ft := &fakeTransport{}
+	d := minidrone.NewMinidroneTransport(ft, minidrone.WithManualTick())
+
+	started := make(chan struct{})
+	second := false
+	ms := New(
+		blocking(started),
+		Step{
+			Name: "second",
+			Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+				second = true
+				return nil
+			},
+		},
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ms.Run(context.Background(), d)
+	}()
+
+	<-started
+	ms.Abort(HoldLand)
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Abort")
+	}
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Index != 0 || !errors.Is(err, ErrAborted) {
+		t.Errorf("Run() = %v, want ErrAborted in step 0", err)
+	}
+	if second {
+		t.Error("the step after the aborted one was run")
+	}
+	if !ft.landed() {
+		t.Error("the drone was not landed")
+	}
+}
+
+func TestAbortReturnHome(t *testing.T) {
+	ft := &fakeTransport{}
+	d := minidrone.NewMinidroneTransport(ft, minidrone.WithManualTick())
+
+	started := make(chan struct{})
+	home := false
+	ms := New(blocking(started))
+	ms.Home = []Step{{
+		Name: "home",
+		Run: func(ctx context.Context, d *minidrone.Minidrone) error {
+			home = true
+			return nil
+		},
+	}}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ms.Run(context.Background(), d)
+	}()
+
+	<-started
+	ms.Abort(HoldReturnHome)
+	<-done
+
+	if !home {
+		t.Error("the home steps were not run")
+	}
+	if !ft.landed() {
+		t.Error("the drone was not landed")
+	}
+}
@@ -0,0 +1,74 @@
+package minidrone
+
+// FlyingMode is the flying mode of a Parrot Swing.
+type FlyingMode int
+
+const (
+	FlyingModeQuad FlyingMode = iota
+	FlyingModePlaneForward
+	FlyingModePlaneBackward
+)
+
+func (f FlyingMode) String() string {
+	switch f {
+	case FlyingModeQuad:
+		return "quadricopter"
+	case FlyingModePlaneForward:
+		return "plane forward"
+	case FlyingModePlaneBackward:
+		return "plane backward"
+	}
+
+	return "unknown"
+}
+
+// PilotingModes are the piloting modes reported by the drone.
+type PilotingModes struct {
+	// AutoTakeOff is true when the drone takes off by itself when thrown.
+	AutoTakeOff bool
+
+	// FlyingMode is the flying mode of a Parrot Swing.
+	FlyingMode FlyingMode
+
+	// PlaneGear is the gear of a Parrot Swing in plane mode, from 1 to 3.
+	PlaneGear int
+}
+
+// PilotingModes returns the last piloting modes reported by the drone.
+func (m *Minidrone) PilotingModes() PilotingModes {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.modes
+}
+
+func (m *Minidrone) processPilotingModes(f frame) {
+	switch {
+	case f.command == cmdAutoTakeOffModeChanged && f.argsLen(1):
+		enabled := f.args[0] != 0
+
+		m.stateMutex.Lock()
+		m.modes.AutoTakeOff = enabled
+		m.stateMutex.Unlock()
+
+		m.publish(AutoTakeOffModeChange, enabled)
+
+	case f.command == cmdFlyingModeChanged && f.argsLen(4):
+		mode := FlyingMode(f.uint32At(0))
+
+		m.stateMutex.Lock()
+		m.modes.FlyingMode = mode
+		m.stateMutex.Unlock()
+
+		m.publish(FlyingModeChange, mode)
+
+	case f.command == cmdPlaneGearBoxChanged && f.argsLen(4):
+		gear := int(f.uint32At(0)) + 1
+
+		m.stateMutex.Lock()
+		m.modes.PlaneGear = gear
+		m.stateMutex.Unlock()
+
+		m.publish(PlaneGearChange, gear)
+	}
+}
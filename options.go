@@ -0,0 +1,49 @@
+package minidrone
+
+// Option configures optional behavior of a Minidrone.
+type Option func(m *Minidrone)
+
+// WithSkipIdlePcmd skips transmitting pcmd frames while the drone is landed
+// and no movement has been requested, reducing BLE airtime and power draw.
+// Pcmd frames are always sent at full rate while the drone is not landed.
+func WithSkipIdlePcmd() Option {
+	return func(m *Minidrone) {
+		m.skipIdlePcmd = true
+	}
+}
+
+// WithManualTick keeps Start from starting the pcmd goroutine. Instead, the
+// caller must call Tick every PcmdInterval from its own loop or timer, so
+// that the driver itself spawns no goroutines. The landings and state
+// refreshes that the pcmd loop would send in the background are then sent from
+// Tick, which blocks until they have been written.
+func WithManualTick() Option {
+	return func(m *Minidrone) {
+		m.manualTick = true
+	}
+}
+
+// WithPriorityLanding sends Land on the priority channel, like Emergency,
+// so that it is not stuck behind other commands.
+func WithPriorityLanding() Option {
+	return func(m *Minidrone) {
+		m.priorityLanding = true
+	}
+}
+
+// WithAutoFlatTrim makes TakeOff flat trim the drone first, and only take off
+// once the drone has confirmed the new trim.
+func WithAutoFlatTrim(enable bool) Option {
+	return func(m *Minidrone) {
+		m.autoFlatTrim = enable
+	}
+}
+
+// WithLowLatency requests shorter BLE connection intervals once connected,
+// on the adapters that support it, as the default intervals of some
+// platforms add a noticeable delay to every movement command.
+func WithLowLatency() Option {
+	return func(m *Minidrone) {
+		m.lowLatency = true
+	}
+}
@@ -0,0 +1,57 @@
+package minidrone
+
+import "errors"
+
+// ErrPcmdRange is returned by Pcmd.Validate when a field is out of range.
+var ErrPcmdRange = errors.New("pcmd value out of range")
+
+// pcmdLen is the length of a PCMD frame.
+const pcmdLen = 19
+
+// Clamp limits Roll, Pitch, Yaw and Gaz to -100..100 and Flag to 0 or 1, so
+// that each of them fits in its byte of the PCMD frame.
+func (p *Pcmd) Clamp() {
+	if p.Flag != 0 {
+		p.Flag = 1
+	}
+	p.Roll = validateAxis(p.Roll)
+	p.Pitch = validateAxis(p.Pitch)
+	p.Yaw = validateAxis(p.Yaw)
+	p.Gaz = validateAxis(p.Gaz)
+}
+
+// Validate returns ErrPcmdRange if any of Roll, Pitch, Yaw or Gaz is outside
+// of -100..100, or if Flag is not 0 or 1.
+func (p Pcmd) Validate() error {
+	c := p
+	c.Clamp()
+	if c != p {
+		return ErrPcmdRange
+	}
+
+	return nil
+}
+
+// encode writes p as a PCMD frame with the sequence number seq into buf,
+// which must be at least pcmdLen bytes long. Every byte of the frame is
+// written, so buf can be reused from one frame to the next.
+func (p Pcmd) encode(buf []byte, seq uint8) {
+	_ = buf[pcmdLen-1]
+
+	buf[0] = frameTypeData
+	buf[1] = seq
+	buf[2] = projectMinidrone
+	buf[3] = 0x00 // Piloting
+	buf[4] = 0x02 // PCMD
+	buf[5] = 0x00
+	buf[6] = byte(p.Flag)
+	buf[7] = byte(p.Roll)
+	buf[8] = byte(p.Pitch)
+	buf[9] = byte(p.Yaw)
+	buf[10] = byte(p.Gaz)
+	putFloat32(buf[11:15], p.Psi)
+	buf[15] = 0x00
+	buf[16] = 0x00
+	buf[17] = 0x00
+	buf[18] = 0x00
+}
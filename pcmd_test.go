@@ -0,0 +1,107 @@
+package minidrone
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPcmdEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		pcmd Pcmd
+		seq  uint8
+		want []byte
+	}{
+		{
+			name: "neutral",
+			pcmd: Pcmd{},
+			seq:  1,
+			want: []byte{0x02, 0x01, 0x02, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			name: "full positive",
+			pcmd: Pcmd{Flag: 1, Roll: 100, Pitch: 100, Yaw: 100, Gaz: 100},
+			seq:  0x7f,
+			want: []byte{0x02, 0x7f, 0x02, 0x00, 0x02, 0x00, 0x01, 0x64, 0x64, 0x64, 0x64, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			name: "full negative",
+			pcmd: Pcmd{Flag: 1, Roll: -100, Pitch: -100, Yaw: -100, Gaz: -100},
+			seq:  0xff,
+			want: []byte{0x02, 0xff, 0x02, 0x00, 0x02, 0x00, 0x01, 0x9c, 0x9c, 0x9c, 0x9c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			name: "psi",
+			pcmd: Pcmd{Psi: 1.5},
+			seq:  2,
+			want: []byte{0x02, 0x02, 0x02, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0x3f, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			name: "negative psi",
+			pcmd: Pcmd{Psi: -90},
+			seq:  3,
+			want: []byte{0x02, 0x03, 0x02, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xb4, 0xc2, 0x00, 0x00, 0x00, 0x00},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// start from a dirty buffer, as encode reuses it
+			buf := bytes.Repeat([]byte{0xaa}, pcmdLen)
+			tt.pcmd.encode(buf, tt.seq)
+			if !bytes.Equal(buf, tt.want) {
+				t.Errorf("encode() = % x, want % x", buf, tt.want)
+			}
+		})
+	}
+}
+
+func TestPcmdClamp(t *testing.T) {
+	tests := []struct {
+		name string
+		pcmd Pcmd
+		want Pcmd
+	}{
+		{
+			name: "in range",
+			pcmd: Pcmd{Flag: 1, Roll: 50, Pitch: -50, Yaw: 100, Gaz: -100},
+			want: Pcmd{Flag: 1, Roll: 50, Pitch: -50, Yaw: 100, Gaz: -100},
+		},
+		{
+			name: "out of range",
+			pcmd: Pcmd{Flag: 5, Roll: 101, Pitch: -101, Yaw: 300, Gaz: -1000},
+			want: Pcmd{Flag: 1, Roll: 100, Pitch: -100, Yaw: 100, Gaz: -100},
+		},
+		{
+			name: "psi is kept",
+			pcmd: Pcmd{Roll: 200, Psi: 45},
+			want: Pcmd{Roll: 100, Psi: 45},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pcmd.Validate()
+			if (err != nil) != (tt.pcmd != tt.want) {
+				t.Errorf("Validate() = %v", err)
+			}
+
+			got := tt.pcmd
+			got.Clamp()
+			if got != tt.want {
+				t.Errorf("Clamp() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPcmdClampEncode(t *testing.T) {
+	p := Pcmd{Flag: 1, Roll: 250, Pitch: -250}
+	p.Clamp()
+
+	buf := make([]byte, pcmdLen)
+	p.encode(buf, 9)
+	if buf[7] != 0x64 || buf[8] != 0x9c {
+		t.Errorf("roll, pitch = %#x, %#x, want 0x64, 0x9c", buf[7], buf[8])
+	}
+}
@@ -0,0 +1,56 @@
+package minidrone
+
+// ProductInfo identifies the drone, as reported in its settings.
+type ProductInfo struct {
+	Name            string
+	SoftwareVersion string
+	HardwareVersion string
+	Serial          string
+	Country         string
+}
+
+// Product returns the product information reported by the drone. The fields
+// are empty until the drone has sent its settings, which it does after Init
+// or RequestAllSettings.
+func (m *Minidrone) Product() ProductInfo {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.product
+}
+
+func (m *Minidrone) processSettingsState(f frame) {
+	if f.command == cmdAllSettingsChanged {
+		m.publish(AllSettingsReceived, nil)
+		return
+	}
+
+	m.stateMutex.Lock()
+	p := &m.product
+	switch f.command {
+	case cmdProductNameChanged:
+		p.Name, _ = f.stringAt(0)
+	case cmdProductVersionChanged:
+		software, next := f.stringAt(0)
+		p.SoftwareVersion = software
+		p.HardwareVersion, _ = f.stringAt(next)
+	case cmdProductSerialHighChanged:
+		// the high part is always reported before the low part
+		p.Serial, _ = f.stringAt(0)
+	case cmdProductSerialLowChanged:
+		low, _ := f.stringAt(0)
+		p.Serial += low
+	case cmdCountryChanged:
+		p.Country, _ = f.stringAt(0)
+	default:
+		m.stateMutex.Unlock()
+		return
+	}
+	product := *p
+	m.stateMutex.Unlock()
+
+	if debug {
+		println("product", product.Name, product.SoftwareVersion)
+	}
+	m.publish(ProductChange, product)
+}
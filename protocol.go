@@ -0,0 +1,180 @@
+package minidrone
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// ARSDK frame data types
+const (
+	frameTypeAck         = 0x01
+	frameTypeData        = 0x02
+	frameTypeLowLatency  = 0x03
+	frameTypeDataWithAck = 0x04
+)
+
+// ARSDK projects
+const (
+	projectCommon    = 0x00
+	projectMinidrone = 0x02
+)
+
+// common project classes
+const (
+	classSettingsState    = 0x03
+	classCommonState      = 0x05
+	classCalibrationState = 0x0e
+	classChargerState     = 0x1d
+)
+
+// common SettingsState commands
+const (
+	cmdAllSettingsChanged       = 0x00
+	cmdProductNameChanged       = 0x02
+	cmdProductVersionChanged    = 0x03
+	cmdProductSerialHighChanged = 0x04
+	cmdProductSerialLowChanged  = 0x05
+	cmdCountryChanged           = 0x06
+)
+
+// minidrone project classes
+const (
+	classPilotingState         = 0x03
+	classSpeedSettingsState    = 0x05
+	classPilotingSettingsState = 0x08
+	classUsbAccessoryState     = 0x0f
+	classNavigationDataState   = 0x12
+)
+
+// minidrone UsbAccessoryState commands
+const (
+	cmdLightState = 0x00
+	cmdClawState  = 0x01
+	cmdGunState   = 0x02
+)
+
+// list flags of the UsbAccessoryState commands
+const (
+	listFlagEmpty  = 0x04
+	listFlagRemove = 0x08
+)
+
+// common CalibrationState commands
+const (
+	cmdMagnetoCalibrationStateChanged   = 0x00
+	cmdMagnetoCalibrationRequiredState  = 0x01
+	cmdMagnetoCalibrationAxisChanged    = 0x02
+	cmdMagnetoCalibrationStartedChanged = 0x03
+)
+
+// common ChargerState commands
+const (
+	cmdCurrentChargeStateChanged = 0x01
+)
+
+// common CommonState commands
+const (
+	cmdAllStatesChanged    = 0x00
+	cmdBatteryStateChanged = 0x01
+)
+
+// minidrone PilotingState commands
+const (
+	cmdFlatTrimChanged    = 0x00
+	cmdFlyingStateChanged = 0x01
+	cmdAlertStateChanged  = 0x02
+
+	cmdAutoTakeOffModeChanged = 0x03
+	cmdFlyingModeChanged      = 0x04
+	cmdPlaneGearBoxChanged    = 0x05
+)
+
+// minidrone SpeedSettingsState commands
+const (
+	cmdMaxVerticalSpeedChanged = 0x00
+	cmdMaxRotationSpeedChanged = 0x01
+	cmdWheelsChanged           = 0x02
+)
+
+// minidrone PilotingSettingsState commands
+const (
+	cmdMaxAltitudeChanged = 0x00
+	cmdMaxTiltChanged     = 0x01
+)
+
+// minidrone NavigationDataState commands
+const (
+	cmdDronePosition   = 0x00
+	cmdDroneSpeed      = 0x01
+	cmdDroneAltitude   = 0x02
+	cmdDroneQuaternion = 0x03
+)
+
+// frame is a notification received from the drone.
+type frame struct {
+	dataType byte
+	seq      byte
+	project  byte
+	class    byte
+	command  uint16
+	args     []byte
+}
+
+// parseFrame splits a raw notification into its header and arguments.
+// Frames that are too short to carry a command, such as syncs, are rejected.
+func parseFrame(data []byte) (f frame, ok bool) {
+	if len(data) < 6 {
+		return f, false
+	}
+
+	f.dataType = data[0]
+	f.seq = data[1]
+	f.project = data[2]
+	f.class = data[3]
+	f.command = binary.LittleEndian.Uint16(data[4:6])
+	f.args = data[6:]
+
+	return f, true
+}
+
+// argsLen reports whether the frame carries at least n bytes of arguments.
+func (f frame) argsLen(n int) bool {
+	return len(f.args) >= n
+}
+
+func (f frame) int16At(i int) int16 {
+	return int16(binary.LittleEndian.Uint16(f.args[i:]))
+}
+
+func (f frame) uint32At(i int) uint32 {
+	return binary.LittleEndian.Uint32(f.args[i:])
+}
+
+func (f frame) float32At(i int) float32 {
+	return float32From(f.args[i:])
+}
+
+// putFloat32 and float32From encode floats as in the ARSDK frames. They only
+// reinterpret the bits, so they do not pull the rest of the math package
+// into TinyGo builds.
+func putFloat32(b []byte, v float32) {
+	binary.LittleEndian.PutUint32(b, math.Float32bits(v))
+}
+
+func float32From(b []byte) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(b))
+}
+
+// stringAt returns the null-terminated string starting at i, and the index
+// just past its terminator.
+func (f frame) stringAt(i int) (string, int) {
+	if i >= len(f.args) {
+		return "", len(f.args)
+	}
+	if n := bytes.IndexByte(f.args[i:], 0); n >= 0 {
+		return string(f.args[i : i+n]), i + n + 1
+	}
+
+	return string(f.args[i:]), len(f.args)
+}
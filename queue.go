@@ -0,0 +1,64 @@
+package minidrone
+
+import (
+	"bytes"
+	"time"
+)
+
+// WithCommandSpacing waits at least spacing between two writes to the
+// command characteristic, for adapters that drop back-to-back writes.
+// A setting identical to the previous command that is sent again within
+// spacing, such as SetMaxAltitude with the same value, is coalesced into it
+// and not written. Actions such as flips, turns and takeoff are always sent.
+func WithCommandSpacing(spacing time.Duration) Option {
+	return func(m *Minidrone) {
+		m.commandSpacing = spacing
+	}
+}
+
+// queueCommand must be called with the commandMutex held. It waits until
+// buf can be written, and returns false if buf repeats the previous command
+// and should be dropped.
+func (m *Minidrone) queueCommand(cmd string, buf []byte) bool {
+	if m.commandSpacing <= 0 {
+		return true
+	}
+
+	wait := m.commandSpacing - time.Since(m.lastCommand)
+	if wait > 0 && idempotent(cmd) && cmd == m.lastCommandName && bytes.Equal(buf[2:], m.lastCommandArgs) {
+		if debug {
+			println("coalesced command", cmd)
+		}
+		return false
+	}
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	return true
+}
+
+// commandWritten must be called with the commandMutex held, after buf was
+// written.
+func (m *Minidrone) commandWritten(cmd string, buf []byte) {
+	if m.commandSpacing <= 0 {
+		return
+	}
+
+	m.lastCommand = time.Now()
+	m.lastCommandName = cmd
+	m.lastCommandArgs = append(m.lastCommandArgs[:0], buf[2:]...)
+}
+
+// idempotent returns true for the commands that set a setting or a state, so
+// that sending them twice in a row has the same effect as sending them once.
+func idempotent(cmd string) bool {
+	switch cmd {
+	case "maxaltitude", "maxtilt", "maxverticalspeed", "maxrotationspeed",
+		"wheels", "autotakeoffmode", "planemode", "quadmode", "headlights",
+		"currentdate", "currenttime", "requestallsettings", "requestallstates":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,46 @@
+package minidrone
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueActionsNotCoalesced(t *testing.T) {
+	tests := []struct {
+		name    string
+		send    func(m *Minidrone) error
+		command [3]byte
+	}{
+		{"front flip", (*Minidrone).FrontFlip, [3]byte{projectMinidrone, 0x04, 0x00}},
+		{"turn", func(m *Minidrone) error { return m.TurnDegrees(90) }, [3]byte{projectMinidrone, 0x04, 0x01}},
+		{"gun", func(m *Minidrone) error { return m.GunControl(0) }, [3]byte{projectMinidrone, 0x10, 0x02}},
+		{"takeoff", (*Minidrone).TakeOff, [3]byte{projectMinidrone, 0x00, 0x01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, ft := startFake(t, WithCommandSpacing(20*time.Millisecond))
+
+			for i := 0; i < 2; i++ {
+				err := tt.send(m)
+				if err != nil {
+					t.Fatalf("send %d = %v", i, err)
+				}
+			}
+
+			if n := ft.count(tt.command[0], tt.command[1], tt.command[2]); n != 2 {
+				t.Errorf("sent %d frames, want 2", n)
+			}
+		})
+	}
+}
+
+func TestQueueSettingsCoalesced(t *testing.T) {
+	m, ft := startFake(t, WithCommandSpacing(200*time.Millisecond))
+
+	m.SetMaxTilt(10)
+	m.SetMaxTilt(10)
+	if n := ft.count(projectMinidrone, 0x02, 0x01); n != 1 {
+		t.Errorf("sent %d max tilt frames, want 1", n)
+	}
+}
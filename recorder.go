@@ -0,0 +1,135 @@
+//go:build !baremetal
+
+package minidrone
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// maxRecorderBuffer is how many bytes of records are kept waiting to be
+// written before new records are dropped.
+const maxRecorderBuffer = 64 * 1024
+
+// kinds of Record
+const (
+	RecordCommand      = "command"
+	RecordNotification = "notification"
+	RecordEvent        = "event"
+)
+
+// Record is one line written by WithRecorder.
+type Record struct {
+	Time time.Time `json:"time"`
+
+	// Kind is RecordCommand, RecordNotification or RecordEvent.
+	Kind string `json:"kind"`
+
+	// Name is the command or event name.
+	Name string `json:"name,omitempty"`
+
+	// Channel is the channel a notification was received on.
+	Channel Channel `json:"channel,omitempty"`
+
+	// Frame is the raw frame of a command or notification.
+	Frame []byte `json:"frame,omitempty"`
+
+	// Error is the error returned when writing a command, if any.
+	Error string `json:"error,omitempty"`
+
+	// Data is the data of an event.
+	Data interface{} `json:"data,omitempty"`
+}
+
+// WithRecorder writes every command and pcmd sent to the drone, every
+// notification received from it, and every event published, as one JSON
+// Record per line to w. Errors writing to w are ignored, so that recording
+// never gets in the way of flying.
+//
+// The records are buffered in memory and written to w from Tick once per
+// pcmd cycle, and by Halt, outside of the driver locks, so that a slow w
+// never holds up the commands. A w that blocks still delays the next pcmd,
+// so it should be a file or a buffered writer rather than a network
+// connection. Records are dropped while more than 64KB are waiting.
+func WithRecorder(w io.Writer) Option {
+	return func(m *Minidrone) {
+		r := &jsonRecorder{w: w}
+		r.enc = json.NewEncoder(&r.buf)
+		m.recorder = r
+	}
+}
+
+type jsonRecorder struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+	enc   *json.Encoder
+
+	// flushMutex keeps the flushes in order
+	flushMutex sync.Mutex
+	w          io.Writer
+	pending    []byte
+}
+
+func (r *jsonRecorder) write(rec Record) {
+	rec.Time = time.Now()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.buf.Len() > maxRecorderBuffer {
+		return
+	}
+
+	if r.enc.Encode(rec) != nil && rec.Data != nil {
+		// some event data, such as a FailsafeRule, cannot be encoded
+		rec.Data = nil
+		r.enc.Encode(rec)
+	}
+}
+
+// flush writes the buffered records to w.
+func (r *jsonRecorder) flush() {
+	r.flushMutex.Lock()
+	defer r.flushMutex.Unlock()
+
+	r.mutex.Lock()
+	r.pending = append(r.pending[:0], r.buf.Bytes()...)
+	r.buf.Reset()
+	r.mutex.Unlock()
+
+	if len(r.pending) > 0 {
+		r.w.Write(r.pending)
+	}
+}
+
+func (r *jsonRecorder) recordCommand(cmd string, frame []byte, err error) {
+	rec := Record{
+		Kind:  RecordCommand,
+		Name:  cmd,
+		Frame: frame,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	r.write(rec)
+}
+
+func (r *jsonRecorder) recordNotification(ch Channel, frame []byte) {
+	r.write(Record{
+		Kind:    RecordNotification,
+		Channel: ch,
+		Frame:   frame,
+	})
+}
+
+func (r *jsonRecorder) recordEvent(event string, data interface{}) {
+	r.write(Record{
+		Kind: RecordEvent,
+		Name: event,
+		Data: data,
+	})
+}
@@ -0,0 +1,23 @@
+//go:build !baremetal
+
+package minidrone
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecorderFlushesFromTick(t *testing.T) {
+	var out bytes.Buffer
+	m, _ := startFake(t, WithRecorder(&out))
+
+	// the commands sent by Start are only buffered
+	if out.Len() != 0 {
+		t.Fatalf("recorder wrote %d bytes before Tick", out.Len())
+	}
+
+	m.Tick()
+	if !bytes.Contains(out.Bytes(), []byte(`"name":"flattrim"`)) {
+		t.Errorf("flat trim not recorded by Tick: %s", out.Bytes())
+	}
+}
@@ -0,0 +1,66 @@
+package minidrone
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WithStateRefresh asks the drone for all of its states and settings again
+// every interval while connected, so that Status() stays current on firmwares
+// that stop pushing notifications, such as the battery level, after long
+// idle periods.
+func WithStateRefresh(interval time.Duration) Option {
+	return func(m *Minidrone) {
+		m.refreshInterval = interval
+	}
+}
+
+// refreshStates is called from the pcmd loop. The requests are sent from
+// their own goroutine, as they wait for the command lock, spacing and acks,
+// which would otherwise hold up the pcmd frames. With WithManualTick, they are
+// left pending for Tick to send instead.
+func (m *Minidrone) refreshStates() {
+	if m.refreshInterval <= 0 || time.Since(m.lastRefresh) < m.refreshInterval {
+		return
+	}
+	m.lastRefresh = time.Now()
+
+	if m.manualTick {
+		m.refreshPending = true
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&m.refreshing, 0, 1) {
+		// the previous refresh is still being sent
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&m.refreshing, 0)
+
+		if debug {
+			println("refreshing states")
+		}
+
+		if err := m.RequestAllStates(); err != nil {
+			return
+		}
+		m.RequestAllSettings()
+	}()
+}
+
+// sendPending sends the land and refresh requests left pending by the last
+// Tick when using WithManualTick. It blocks Tick until they are written.
+func (m *Minidrone) sendPending() {
+	if m.landPending {
+		m.landPending = false
+		m.Land()
+	}
+
+	if m.refreshPending {
+		m.refreshPending = false
+		if m.RequestAllStates() == nil {
+			m.RequestAllSettings()
+		}
+	}
+}
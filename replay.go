@@ -0,0 +1,103 @@
+//go:build !baremetal
+
+package minidrone
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Replay sends the commands and pcmds of a recording made with WithRecorder
+// to the drone again, with their original timing. Notifications, events and
+// commands that failed when recorded are skipped. Takeoff, landing and flips
+// are sent with their methods, so arming and disabled flips still apply.
+func (m *Minidrone) Replay(r io.Reader) error {
+	return m.ReplayContext(context.Background(), r)
+}
+
+// ReplayContext is like Replay, but stops with the context error when ctx is
+// done before the end of the recording. The Pcmd is left as it was when
+// stopping, so call Hover or Land as needed.
+func (m *Minidrone) ReplayContext(ctx context.Context, r io.Reader) error {
+	dec := json.NewDecoder(r)
+
+	var previous time.Time
+	for {
+		var rec Record
+		err := dec.Decode(&rec)
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+
+		if rec.Kind != RecordCommand || rec.Error != "" || len(rec.Frame) < 6 {
+			continue
+		}
+
+		if !previous.IsZero() {
+			err = sleepContext(ctx, rec.Time.Sub(previous))
+			if err != nil {
+				return err
+			}
+		}
+		previous = rec.Time
+
+		err = m.replayCommand(rec)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (m *Minidrone) replayCommand(rec Record) error {
+	frame := rec.Frame
+	switch rec.Name {
+	case PcmdCommand:
+		if len(frame) < pcmdLen {
+			return nil
+		}
+
+		m.pcmdMutex.Lock()
+		defer m.pcmdMutex.Unlock()
+
+		m.lastMove = time.Now()
+		m.Pcmd = Pcmd{
+			Flag:  int(frame[6]),
+			Roll:  int(int8(frame[7])),
+			Pitch: int(int8(frame[8])),
+			Yaw:   int(int8(frame[9])),
+			Gaz:   int(int8(frame[10])),
+			Psi:   float32From(frame[11:15]),
+		}
+		return nil
+
+	// commands with checks, such as arming or disabled flips, go through
+	// their methods so that replaying cannot bypass them
+	case "emergency":
+		return m.Emergency()
+	case "takeoff":
+		return m.TakeOff()
+	case "land":
+		return m.Land()
+	case AnimationFrontFlip.String():
+		return m.FrontFlip()
+	case AnimationBackFlip.String():
+		return m.BackFlip()
+	case AnimationRightFlip.String():
+		return m.RightFlip()
+	case AnimationLeftFlip.String():
+		return m.LeftFlip()
+	}
+
+	if len(frame) < 6 {
+		return nil
+	}
+
+	// writeCommand sends the recorded frame with a sequence number of this
+	// connection
+	return m.writeCommand(rec.Name, append([]byte(nil), frame...))
+}
@@ -0,0 +1,210 @@
+package minidrone
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotArmed is returned by TakeOff when arming is required and the
+	// drone has not been armed.
+	ErrNotArmed = errors.New("drone is not armed")
+
+	// ErrFlipsDisabled is returned by the flip commands when flips have
+	// been disabled.
+	ErrFlipsDisabled = errors.New("flips are disabled")
+
+	// ErrPanic is matched by the PanicError returned by SafeFly when the
+	// flight code panicked.
+	ErrPanic = errors.New("flight code panicked")
+)
+
+// WithClassroomMode bundles the settings for flying in a classroom: speed is
+// capped at 25, altitude at 1.5m, flips are disabled, the drone hovers if no
+// movement command is received for 300ms, lands when the battery drops
+// below 15%, and must be armed before every takeoff.
+func WithClassroomMode() Option {
+	return func(m *Minidrone) {
+		WithSpeedLimit(25)(m)
+		m.maxAltitude = 1.5
+		m.noFlips = true
+		m.watchdog = 300 * time.Millisecond
+		m.armingRequired = true
+		m.addPresetFailsafe(FailsafeRule{
+			Name:      "classroom low battery",
+			Condition: All(WhileFlying(), BatteryBelow(15)),
+			Action:    FailsafeLand,
+		})
+	}
+}
+
+// WithWatchdog resets the Pcmd to hover when no movement command has been
+// issued within window, so that a crashed controller program cannot leave
+// the drone flying off at speed. Programs that set the Pcmd field directly
+// instead of calling the movement methods will be reset every window.
+func WithWatchdog(window time.Duration) Option {
+	return func(m *Minidrone) {
+		m.watchdog = window
+	}
+}
+
+// WithSpeedLimit clamps every axis of the Pcmd sent to the drone to max
+// percent, whatever the caller asks for. A lower limit set by another
+// option, such as WithClassroomMode, is kept, and a max of 0 or less does
+// not change the limit.
+func WithSpeedLimit(max int) Option {
+	return func(m *Minidrone) {
+		if max <= 0 {
+			return
+		}
+		if m.speedLimit <= 0 || max < m.speedLimit {
+			m.speedLimit = max
+		}
+	}
+}
+
+// WithMaxFlightTime lands the drone once it has been flying for longer than
+// max since takeoff, and publishes a FlightTimeExceeded event.
+func WithMaxFlightTime(max time.Duration) Option {
+	return func(m *Minidrone) {
+		m.maxFlightTime = max
+	}
+}
+
+// Arm allows the next takeoff when arming is required.
+// The drone is disarmed again once it has landed.
+func (m *Minidrone) Arm() {
+	m.stateMutex.Lock()
+	m.armed = true
+	m.stateMutex.Unlock()
+}
+
+// Disarm prevents takeoff until Arm is called again.
+func (m *Minidrone) Disarm() {
+	m.stateMutex.Lock()
+	m.armed = false
+	m.stateMutex.Unlock()
+}
+
+func (m *Minidrone) isArmed() bool {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return !m.armingRequired || m.armed
+}
+
+// limitSpeed clamps an axis value to the speed limit, if there is one.
+func (m *Minidrone) limitSpeed(val int) int {
+	switch {
+	case m.speedLimit <= 0:
+		return val
+	case val > m.speedLimit:
+		return m.speedLimit
+	case val < -m.speedLimit:
+		return -m.speedLimit
+	}
+
+	return val
+}
+
+// checkWatchdog resets the Pcmd to hover when no movement command has been
+// issued within the watchdog window. Must be called with the pcmdMutex held.
+func (m *Minidrone) checkWatchdog() {
+	if m.watchdog <= 0 || m.Pcmd == (Pcmd{}) {
+		return
+	}
+
+	if time.Since(m.lastMove) > m.watchdog {
+		if debug {
+			println("watchdog: hover")
+		}
+		m.Pcmd = Pcmd{}
+		m.resetSlew()
+	}
+}
+
+// checkFlightTime lands the drone when the current flight has lasted longer
+// than the max flight time.
+func (m *Minidrone) checkFlightTime() {
+	if m.maxFlightTime <= 0 {
+		return
+	}
+
+	m.stateMutex.Lock()
+	exceeded := !m.flightStart.IsZero() && !m.flightTimeExceeded &&
+		time.Since(m.flightStart) > m.maxFlightTime
+	if exceeded {
+		m.flightTimeExceeded = true
+	}
+	m.stateMutex.Unlock()
+
+	if !exceeded {
+		return
+	}
+
+	if debug {
+		println("max flight time exceeded: land")
+	}
+	m.publish(FlightTimeExceeded, m.maxFlightTime)
+	m.landFromTick()
+}
+
+// landFromTick hovers and lands from the pcmd loop. Land is sent from its own
+// goroutine, as it can wait for the command lock, spacing and acks, and the
+// pcmd frames must keep flowing meanwhile, all the more so on a congested
+// link. With WithManualTick, Land is left pending for Tick to send instead.
+func (m *Minidrone) landFromTick() {
+	m.hoverNow()
+	if m.manualTick {
+		m.landPending = true
+		return
+	}
+	go m.Land()
+}
+
+// PanicError is returned by SafeFly when the flight code panicked, with the
+// value it panicked with. It matches ErrPanic with errors.Is.
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	msg := "unknown value"
+	switch v := e.Value.(type) {
+	case error:
+		msg = v.Error()
+	case string:
+		msg = v
+	}
+
+	return ErrPanic.Error() + ": " + msg
+}
+
+// Unwrap returns ErrPanic.
+func (e *PanicError) Unwrap() error {
+	return ErrPanic
+}
+
+// SafeFly runs the flight code fn, and makes sure the drone does not keep
+// flying unattended if fn fails: when fn panics or returns an error, the
+// drone is told to land, or to stop its motors if it cannot be told to land.
+// A panic is recovered and returned as a *PanicError.
+func SafeFly(d *Minidrone, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if debug {
+				println("flight code panicked: land")
+			}
+			err = &PanicError{Value: r}
+		}
+
+		if err != nil {
+			d.Hover()
+			if d.Land() != nil {
+				d.Emergency()
+			}
+		}
+	}()
+
+	return fn()
+}
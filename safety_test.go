@@ -0,0 +1,43 @@
+package minidrone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSafeFlyPanic(t *testing.T) {
+	m, ft := startFake(t)
+
+	err := SafeFly(m, func() error {
+		var p *Pcmd
+		p.Clamp()
+		return nil
+	})
+	if !errors.Is(err, ErrPanic) {
+		t.Fatalf("SafeFly() = %v, want ErrPanic", err)
+	}
+
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value == nil {
+		t.Fatalf("SafeFly() = %v, want the panic value", err)
+	}
+	if perr.Error() == ErrPanic.Error() {
+		t.Errorf("Error() = %q, does not describe the panic", perr.Error())
+	}
+
+	if ft.command(projectMinidrone, 0x00, 0x03) == nil {
+		t.Error("no land command sent")
+	}
+}
+
+func TestSafeFlyOK(t *testing.T) {
+	m, ft := startFake(t)
+
+	err := SafeFly(m, func() error { return nil })
+	if err != nil {
+		t.Fatalf("SafeFly() = %v", err)
+	}
+	if ft.command(projectMinidrone, 0x00, 0x03) != nil {
+		t.Error("land command sent")
+	}
+}
@@ -0,0 +1,132 @@
+package minidrone
+
+// Limit is a setting applied by the drone, with the range it accepts.
+type Limit struct {
+	Current float32
+	Min     float32
+	Max     float32
+}
+
+// PilotingSettings are the piloting limits reported by the drone.
+type PilotingSettings struct {
+	// MaxAltitude is in meters.
+	MaxAltitude Limit
+
+	// MaxTilt is in degrees.
+	MaxTilt Limit
+}
+
+// PilotingSettings returns the last piloting limits reported by the drone,
+// which are echoed back whenever they are set.
+func (m *Minidrone) PilotingSettings() PilotingSettings {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.pilotingSettings
+}
+
+// SetMaxTilt sets the maximum tilt the Minidrone may fly at, in degrees.
+func (m *Minidrone) SetMaxTilt(degrees float32) (err error) {
+	buf := []byte{0x02, 0x00, 0x02, 0x02, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+	putFloat32(buf[6:], degrees)
+	return m.writeCommand("maxtilt", buf)
+}
+
+// limitAt decodes the current, min and max floats of a settings state.
+func (f frame) limitAt(i int) Limit {
+	return Limit{
+		Current: f.float32At(i),
+		Min:     f.float32At(i + 4),
+		Max:     f.float32At(i + 8),
+	}
+}
+
+func (m *Minidrone) processPilotingSettingsState(f frame) {
+	if !f.argsLen(12) {
+		return
+	}
+	limit := f.limitAt(0)
+
+	switch f.command {
+	case cmdMaxAltitudeChanged:
+		m.stateMutex.Lock()
+		m.pilotingSettings.MaxAltitude = limit
+		m.stateMutex.Unlock()
+
+		m.publish(MaxAltitudeChange, limit)
+
+	case cmdMaxTiltChanged:
+		m.stateMutex.Lock()
+		m.pilotingSettings.MaxTilt = limit
+		m.stateMutex.Unlock()
+
+		m.publish(MaxTiltChange, limit)
+	}
+}
+
+// SpeedSettings are the speed limits reported by the drone.
+type SpeedSettings struct {
+	// MaxVerticalSpeed is in meters per second.
+	MaxVerticalSpeed Limit
+
+	// MaxRotationSpeed is in degrees per second.
+	MaxRotationSpeed Limit
+
+	// Wheels is true when the drone has been told the wheels are attached.
+	Wheels bool
+}
+
+// SpeedSettings returns the last speed limits reported by the drone, which
+// are echoed back whenever they are set.
+func (m *Minidrone) SpeedSettings() SpeedSettings {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.speedSettings
+}
+
+// SetMaxVerticalSpeed sets the maximum vertical speed of the Minidrone,
+// in meters per second.
+func (m *Minidrone) SetMaxVerticalSpeed(speed float32) (err error) {
+	buf := []byte{0x02, 0x00, 0x02, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	putFloat32(buf[6:], speed)
+	return m.writeCommand("maxverticalspeed", buf)
+}
+
+// SetMaxRotationSpeed sets the maximum rotation speed of the Minidrone,
+// in degrees per second.
+func (m *Minidrone) SetMaxRotationSpeed(speed float32) (err error) {
+	buf := []byte{0x02, 0x00, 0x02, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00}
+	putFloat32(buf[6:], speed)
+	return m.writeCommand("maxrotationspeed", buf)
+}
+
+func (m *Minidrone) processSpeedSettingsState(f frame) {
+	switch f.command {
+	case cmdMaxVerticalSpeedChanged, cmdMaxRotationSpeedChanged:
+		if !f.argsLen(12) {
+			return
+		}
+		limit := f.limitAt(0)
+
+		event := MaxVerticalSpeedChange
+		m.stateMutex.Lock()
+		if f.command == cmdMaxVerticalSpeedChanged {
+			m.speedSettings.MaxVerticalSpeed = limit
+		} else {
+			m.speedSettings.MaxRotationSpeed = limit
+			event = MaxRotationSpeedChange
+		}
+		m.stateMutex.Unlock()
+
+		m.publish(event, limit)
+
+	case cmdWheelsChanged:
+		if !f.argsLen(1) {
+			return
+		}
+		m.stateMutex.Lock()
+		m.speedSettings.Wheels = f.args[0] != 0
+		m.stateMutex.Unlock()
+	}
+}
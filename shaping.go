@@ -0,0 +1,55 @@
+package minidrone
+
+import "math"
+
+// Deadzone returns 0 when value, normalized to -1..1, is within deadzone of
+// the center, and rescales the rest of the range so that the output still
+// starts at 0 and reaches -1 and 1.
+func Deadzone(value, deadzone float64) float64 {
+	value = clampUnit(value)
+	if deadzone <= 0 {
+		return value
+	}
+	if deadzone >= 1 || math.Abs(value) <= deadzone {
+		return 0
+	}
+
+	return math.Copysign((math.Abs(value)-deadzone)/(1-deadzone), value)
+}
+
+// Expo applies an exponential curve to value, normalized to -1..1. An expo of
+// 0 is linear and an expo of 1 is fully cubic, which gives finer control
+// around the center of the stick while still reaching full deflection.
+func Expo(value, expo float64) float64 {
+	value = clampUnit(value)
+	expo = math.Max(0, math.Min(1, expo))
+
+	return (1-expo)*value + expo*value*value*value
+}
+
+// StickShape maps raw stick values to Pcmd percentages.
+type StickShape struct {
+	// Deadzone is the fraction of the stick travel around the center
+	// that is ignored, such as 0.1.
+	Deadzone float64
+
+	// Expo is the amount of exponential curve from 0 (linear) to 1.
+	Expo float64
+}
+
+// Percent maps data, with a full deflection of offset such as 32768 for a
+// joystick axis, to a value between -100 and 100 that can be used with
+// Move or for each field of the Pcmd.
+func (s StickShape) Percent(data, offset float64) int {
+	if offset == 0 {
+		return 0
+	}
+
+	value := Expo(Deadzone(data/offset, s.Deadzone), s.Expo)
+
+	return validateAxis(int(math.Round(value * 100)))
+}
+
+func clampUnit(value float64) float64 {
+	return math.Max(-1, math.Min(1, value))
+}
@@ -0,0 +1,70 @@
+package minidrone
+
+import "time"
+
+// WithSlewRate ramps Roll, Pitch, Yaw and Gaz toward the values set on the
+// Pcmd instead of jumping to them, changing each axis by at most rate
+// percent per second. For example a rate of 200 takes half a second to go
+// from hover to full stick, which smooths out button based controllers.
+func WithSlewRate(rate int) Option {
+	return func(m *Minidrone) {
+		m.slewRate = rate
+	}
+}
+
+// slewPcmd returns the pcmd to send on the way to target. It must be called
+// with the pcmdMutex held.
+func (m *Minidrone) slewPcmd(target Pcmd) Pcmd {
+	if m.slewRate <= 0 {
+		return target
+	}
+
+	now := time.Now()
+	step := PcmdInterval
+	if !m.lastSlew.IsZero() {
+		step = now.Sub(m.lastSlew)
+	}
+	m.lastSlew = now
+
+	max := int(int64(m.slewRate) * int64(step) / int64(time.Second))
+	if max < 1 {
+		max = 1
+	}
+
+	m.slewed.Flag = target.Flag
+	m.slewed.Psi = target.Psi
+	m.slewed.Roll = slewAxis(m.slewed.Roll, target.Roll, max)
+	m.slewed.Pitch = slewAxis(m.slewed.Pitch, target.Pitch, max)
+	m.slewed.Yaw = slewAxis(m.slewed.Yaw, target.Yaw, max)
+	m.slewed.Gaz = slewAxis(m.slewed.Gaz, target.Gaz, max)
+
+	return m.slewed
+}
+
+// hoverNow hovers at once, without ramping down at the slew rate, for the
+// failsafe, watchdog and landing paths that must stop the drone right away.
+func (m *Minidrone) hoverNow() {
+	m.pcmdMutex.Lock()
+	defer m.pcmdMutex.Unlock()
+
+	m.Pcmd = Pcmd{}
+	m.resetSlew()
+}
+
+// resetSlew makes the next pcmd start from hover. It must be called with the
+// pcmdMutex held.
+func (m *Minidrone) resetSlew() {
+	m.slewed = Pcmd{}
+	m.lastSlew = time.Time{}
+}
+
+func slewAxis(current, target, max int) int {
+	switch {
+	case target > current+max:
+		return current + max
+	case target < current-max:
+		return current - max
+	}
+
+	return target
+}
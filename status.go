@@ -0,0 +1,90 @@
+package minidrone
+
+import "sync/atomic"
+
+// Status is a snapshot of the current state of the Minidrone.
+type Status struct {
+	// Flying is true when the drone is hovering or flying.
+	Flying bool
+
+	// FlyingState is the last flying state reported by the drone,
+	// such as FlyingStateHovering.
+	FlyingState FlyingState
+
+	// Modes are the piloting modes reported by the drone.
+	Modes PilotingModes
+
+	// Battery is the battery level in percent, or -1 if it has not been
+	// reported yet.
+	Battery int
+
+	// Charge is whether the battery is charging or discharging.
+	Charge ChargeState
+
+	// RSSI is the last signal strength passed to UpdateRSSI, or 0 if unknown.
+	RSSI int16
+
+	// LinkErrors is the number of failed pcmd writes.
+	LinkErrors int
+
+	// Telemetry is the last telemetry reported by the drone.
+	Telemetry Telemetry
+
+	// Flights is the number of flights and total airtime, including the
+	// flight in progress.
+	Flights FlightStats
+
+	// Product identifies the drone, once it has reported its settings.
+	Product ProductInfo
+
+	// Version identifies the running driver build.
+	Version VersionInfo
+}
+
+// Status returns a snapshot of the current state of the Minidrone.
+func (m *Minidrone) Status() Status {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return Status{
+		Flying:      m.IsFlying(),
+		FlyingState: m.FlyingState(),
+		Modes:       m.modes,
+		Battery:     m.battery,
+		Charge:      m.charge,
+		RSSI:        m.rssi,
+		LinkErrors:  m.linkErrors,
+		Telemetry:   m.telemetry,
+		Flights:     m.flightStats(),
+		Product:     m.product,
+		Version:     BuildInfo(),
+	}
+}
+
+// FlyingState returns the last flying state reported by the drone,
+// such as FlyingStateHovering.
+func (m *Minidrone) FlyingState() FlyingState {
+	return FlyingState(atomic.LoadInt32(&m.flyingState))
+}
+
+// IsFlying returns true when the drone is hovering or flying.
+func (m *Minidrone) IsFlying() bool {
+	return atomic.LoadInt32(&m.flying) != 0
+}
+
+func (m *Minidrone) setFlying(flying bool) {
+	var v int32
+	if flying {
+		v = 1
+	}
+	atomic.StoreInt32(&m.flying, v)
+	m.Flying = flying
+}
+
+// UpdateRSSI sets the signal strength of the connection to the drone,
+// for example from the RSSI of a recent scan result.
+func (m *Minidrone) UpdateRSSI(rssi int16) {
+	m.stateMutex.Lock()
+	m.rssi = rssi
+	m.stateMutex.Unlock()
+}
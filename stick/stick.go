@@ -0,0 +1,112 @@
+// Package stick maps the raw readings of joysticks, such as TinyGo ADC inputs
+// or desktop gamepad axes, to the percentages used by a Minidrone Pcmd.
+package stick
+
+import (
+	minidrone "github.com/hybridgroup/tinygo-minidrone"
+)
+
+// Axis is one axis of a stick, with the raw readings at its ends and center.
+type Axis struct {
+	// Min, Center and Max are the raw readings at full deflection in one
+	// direction, at rest and at full deflection in the other direction.
+	Min, Center, Max int
+
+	// Deadzone is the fraction of the travel around the center that is
+	// ignored, such as 0.1.
+	Deadzone float64
+
+	// Expo is the amount of exponential curve from 0 (linear) to 1.
+	Expo float64
+
+	// Invert reverses the direction of the axis.
+	Invert bool
+}
+
+// ADC returns an Axis for a 16 bit TinyGo ADC reading, centered at rest.
+func ADC() Axis {
+	return Axis{Min: 0, Center: 32767, Max: 65535, Deadzone: 0.1}
+}
+
+// Gamepad returns an Axis for a signed 16 bit gamepad reading.
+func Gamepad() Axis {
+	return Axis{Min: -32768, Center: 0, Max: 32767, Deadzone: 0.1}
+}
+
+// Calibrate sets the center of the axis to the reading at rest, as sticks
+// rarely rest exactly in the middle of their range.
+func (a *Axis) Calibrate(raw int) {
+	a.Center = raw
+}
+
+// Extend widens the range of the axis to include raw, so that moving the
+// stick to its ends once is enough to calibrate it.
+func (a *Axis) Extend(raw int) {
+	if raw < a.Min {
+		a.Min = raw
+	}
+	if raw > a.Max {
+		a.Max = raw
+	}
+}
+
+// Percent maps a raw reading to a value between -100 and 100.
+func (a Axis) Percent(raw int) int {
+	offset := a.Max - a.Center
+	if raw < a.Center {
+		offset = a.Center - a.Min
+	}
+
+	shape := minidrone.StickShape{Deadzone: a.Deadzone, Expo: a.Expo}
+	percent := shape.Percent(float64(raw-a.Center), float64(offset))
+	if a.Invert {
+		percent = -percent
+	}
+
+	return percent
+}
+
+// Stick is a stick with two axes.
+type Stick struct {
+	X, Y Axis
+}
+
+// Percent maps raw x and y readings to values between -100 and 100.
+func (s Stick) Percent(x, y int) (int, int) {
+	return s.X.Percent(x), s.Y.Percent(y)
+}
+
+// Sticks are the two sticks of a remote, in the usual mode 2 layout: the
+// left stick turns and climbs, the right stick rolls and pitches.
+type Sticks struct {
+	Left, Right Stick
+
+	// Speed scales the output, from 1 to 100 percent, or full speed if zero.
+	Speed int
+}
+
+// Pcmd maps raw readings of both sticks to a Pcmd.
+func (s Sticks) Pcmd(leftX, leftY, rightX, rightY int) minidrone.Pcmd {
+	yaw, gaz := s.Left.Percent(leftX, leftY)
+	roll, pitch := s.Right.Percent(rightX, rightY)
+
+	p := minidrone.Pcmd{
+		Roll:  s.scale(roll),
+		Pitch: s.scale(pitch),
+		Yaw:   s.scale(yaw),
+		Gaz:   s.scale(gaz),
+	}
+	if p.Roll != 0 || p.Pitch != 0 {
+		p.Flag = 1
+	}
+
+	return p
+}
+
+func (s Sticks) scale(percent int) int {
+	if s.Speed <= 0 || s.Speed >= 100 {
+		return percent
+	}
+
+	return percent * s.Speed / 100
+}
@@ -0,0 +1,156 @@
+package minidrone
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// SupervisorState is the state of the connection run by a Supervisor.
+type SupervisorState int
+
+const (
+	SupervisorScanning SupervisorState = iota
+	SupervisorConnecting
+	SupervisorStarting
+	SupervisorRunning
+	SupervisorDisconnected
+)
+
+func (s SupervisorState) String() string {
+	switch s {
+	case SupervisorScanning:
+		return "scanning"
+	case SupervisorConnecting:
+		return "connecting"
+	case SupervisorStarting:
+		return "starting"
+	case SupervisorRunning:
+		return "running"
+	case SupervisorDisconnected:
+		return "disconnected"
+	}
+
+	return "unknown"
+}
+
+// Supervisor scans for the drone with an address, connects to it, starts
+// it, and reconnects whenever the connection is lost.
+type Supervisor struct {
+	// Drone is the drone run by the supervisor. It is kept across
+	// reconnects, so handlers only need to be set on it once.
+	Drone *Minidrone
+
+	// RetryInterval is how long to wait before reconnecting.
+	RetryInterval time.Duration
+
+	// OnStatus is called whenever the state of the connection changes, with
+	// the error that caused it, if any.
+	OnStatus func(state SupervisorState, err error)
+
+	adapter *bluetooth.Adapter
+	address string
+	device  bluetooth.Device
+}
+
+// NewSupervisor returns a new Supervisor for the drone with address, such
+// as "4C:D2:6C:17:82:6E", configured with any of the given options. The
+// adapter must already be enabled.
+func NewSupervisor(adapter *bluetooth.Adapter, address string, opts ...Option) *Supervisor {
+	s := &Supervisor{
+		RetryInterval: time.Second,
+		adapter:       adapter,
+		address:       address,
+	}
+	s.Drone = NewMinidroneTransport(NewBLETransport(&s.device), opts...)
+
+	return s
+}
+
+// Run connects to the drone and keeps it connected until ctx is done. It then
+// lands the drone, disconnects, and returns the context error. Run sets the
+// connect handler of the adapter.
+func (s *Supervisor) Run(ctx context.Context) error {
+	// each connection attempt gets its own channel, so that the disconnect
+	// of a failed attempt cannot end the next connection
+	var mu sync.Mutex
+	var disconnected chan struct{}
+	s.adapter.SetConnectHandler(func(device bluetooth.Device, connected bool) {
+		if !connected && device.Address.String() == s.address {
+			mu.Lock()
+			select {
+			case disconnected <- struct{}{}:
+			default:
+			}
+			mu.Unlock()
+		}
+	})
+
+	for {
+		mu.Lock()
+		disconnected = make(chan struct{}, 1)
+		current := disconnected
+		mu.Unlock()
+
+		err := s.connect(ctx)
+		if err == nil {
+			s.status(SupervisorRunning, nil)
+
+			select {
+			case <-current:
+				s.Drone.stopPcmd()
+				s.status(SupervisorDisconnected, nil)
+			case <-ctx.Done():
+				s.Drone.Halt()
+				s.Drone.Disconnect()
+				return ctx.Err()
+			}
+		} else {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.status(SupervisorDisconnected, err)
+		}
+
+		err = sleepContext(ctx, s.RetryInterval)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// connect scans for the drone, connects to it and starts it.
+func (s *Supervisor) connect(ctx context.Context) (err error) {
+	s.status(SupervisorScanning, nil)
+	result, err := scanAddress(ctx, s.adapter, s.address)
+	if err != nil {
+		return err
+	}
+
+	s.status(SupervisorConnecting, nil)
+	s.device, err = s.adapter.Connect(result.Address, bluetooth.ConnectionParams{})
+	if err != nil {
+		return err
+	}
+
+	s.status(SupervisorStarting, nil)
+	err = s.Drone.StartContext(ctx)
+	if err != nil {
+		s.device.Disconnect()
+		return err
+	}
+
+	return nil
+}
+
+func (s *Supervisor) status(state SupervisorState, err error) {
+	if debug {
+		println("supervisor", state.String())
+	}
+
+	if s.OnStatus != nil {
+		s.OnStatus(state, err)
+	}
+}
@@ -0,0 +1,107 @@
+package minidrone
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoDrone is returned by a Swarm for a drone index it does not have.
+var ErrNoDrone = errors.New("no drone with this index in the swarm")
+
+// Swarm runs the same commands on several Minidrones.
+type Swarm struct {
+	// Drones are the drones of the swarm. The index of a drone in Drones is
+	// the id passed to event handlers and to Identify.
+	Drones []*Minidrone
+
+	// Stagger is how long to wait between sending a command to one drone
+	// and the next, so that they do not all take off or land at once.
+	Stagger time.Duration
+}
+
+// NewSwarm returns a new Swarm of the drones.
+func NewSwarm(drones ...*Minidrone) *Swarm {
+	return &Swarm{
+		Drones: drones,
+	}
+}
+
+// Start starts each drone in turn. It stops at the first drone that fails
+// to start and returns its error.
+func (s *Swarm) Start() error {
+	for _, d := range s.Drones {
+		err := d.Start()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Halt lands all of the drones and stops their drivers.
+func (s *Swarm) Halt() error {
+	return s.each(false, (*Minidrone).Halt)
+}
+
+// TakeOffAll tells all of the drones to take off, waiting Stagger between
+// each of them.
+func (s *Swarm) TakeOffAll() error {
+	return s.each(true, (*Minidrone).TakeOff)
+}
+
+// LandAll tells all of the drones to land, waiting Stagger between each of
+// them.
+func (s *Swarm) LandAll() error {
+	return s.each(true, (*Minidrone).Land)
+}
+
+// HoverAll tells all of the drones to stop moving and hover in place.
+func (s *Swarm) HoverAll() error {
+	return s.each(false, (*Minidrone).Hover)
+}
+
+// EmergencyAll sets all of the drones into emergency mode, without waiting
+// between them.
+func (s *Swarm) EmergencyAll() error {
+	return s.each(false, (*Minidrone).Emergency)
+}
+
+// Identify blinks the lights of the drone with the id, or wiggles it if it
+// is flying, to find out which physical drone it is.
+func (s *Swarm) Identify(id int) error {
+	if id < 0 || id >= len(s.Drones) {
+		return ErrNoDrone
+	}
+
+	return s.Drones[id].Identify()
+}
+
+// OnEvent sets the handler that is called for every event published by any
+// of the drones, with the id of the drone. It replaces the event handler set
+// on each of the drones.
+func (s *Swarm) OnEvent(handler func(id int, event string, data interface{})) {
+	for i, d := range s.Drones {
+		id := i
+		d.OnEvent(func(event string, data interface{}) {
+			handler(id, event, data)
+		})
+	}
+}
+
+// each runs fn on every drone, even when it fails on some of them so that
+// a command such as Land still reaches the others, and returns the first
+// error.
+func (s *Swarm) each(stagger bool, fn func(*Minidrone) error) (err error) {
+	for i, d := range s.Drones {
+		if stagger && i > 0 && s.Stagger > 0 {
+			time.Sleep(s.Stagger)
+		}
+
+		if e := fn(d); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,55 @@
+package minidrone
+
+import "time"
+
+// TakeoffProfile is a gentle Gaz ramp applied as soon as the drone reports
+// that it is taking off, to soften the initial jump. The ramp starts at Gaz
+// and returns linearly to zero over Duration. A negative Gaz counteracts the
+// climb, which keeps light cargo from being flung out of the claw.
+type TakeoffProfile struct {
+	Gaz      int
+	Duration time.Duration
+}
+
+var (
+	// ClawTakeoffProfile is a soft-start profile for drones carrying
+	// light cargo in the claw.
+	ClawTakeoffProfile = TakeoffProfile{Gaz: -50, Duration: 1500 * time.Millisecond}
+
+	// CargoTakeoffProfile is a softer profile for heavier payloads.
+	CargoTakeoffProfile = TakeoffProfile{Gaz: -30, Duration: 1000 * time.Millisecond}
+)
+
+// WithTakeoffProfile applies the TakeoffProfile after every takeoff.
+func WithTakeoffProfile(profile TakeoffProfile) Option {
+	return func(m *Minidrone) {
+		m.takeoffProfile = profile
+	}
+}
+
+func (m *Minidrone) startTakeoffRamp() {
+	if m.takeoffProfile.Duration == 0 {
+		return
+	}
+
+	m.pcmdMutex.Lock()
+	m.takeoffRamp = time.Now()
+	m.pcmdMutex.Unlock()
+}
+
+// takeoffGaz returns the Gaz offset of the takeoff ramp in progress.
+// Must be called with the pcmdMutex held.
+func (m *Minidrone) takeoffGaz() int {
+	if m.takeoffRamp.IsZero() {
+		return 0
+	}
+
+	elapsed := time.Since(m.takeoffRamp)
+	if elapsed >= m.takeoffProfile.Duration {
+		m.takeoffRamp = time.Time{}
+		return 0
+	}
+
+	remaining := m.takeoffProfile.Duration - elapsed
+	return int(int64(m.takeoffProfile.Gaz) * int64(remaining) / int64(m.takeoffProfile.Duration))
+}
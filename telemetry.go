@@ -0,0 +1,198 @@
+package minidrone
+
+// AlertState is the alert reported by the drone.
+type AlertState int
+
+const (
+	AlertNone AlertState = iota
+	AlertUser
+	AlertCutOut
+	AlertCriticalBattery
+	AlertLowBattery
+)
+
+func (a AlertState) String() string {
+	switch a {
+	case AlertNone:
+		return "none"
+	case AlertUser:
+		return "user"
+	case AlertCutOut:
+		return "cut out"
+	case AlertCriticalBattery:
+		return "critical battery"
+	case AlertLowBattery:
+		return "low battery"
+	}
+
+	return "unknown"
+}
+
+// Position is the position of the drone relative to its takeoff point.
+type Position struct {
+	// X, Y and Z are in centimeters.
+	X, Y, Z int16
+
+	// Psi is the heading in degrees, from -180 to 180.
+	Psi int16
+}
+
+// Speed is the speed of the drone on each axis, in centimeters per second.
+type Speed struct {
+	X, Y, Z int16
+}
+
+// Attitude is the orientation of the drone as a quaternion.
+type Attitude struct {
+	W, X, Y, Z float32
+}
+
+// Telemetry is the navigation data reported by the drone.
+type Telemetry struct {
+	Position Position
+	Speed    Speed
+	Attitude Attitude
+
+	// Altitude is the altitude above the takeoff point in millimeters.
+	Altitude int32
+
+	Alert AlertState
+}
+
+// Telemetry returns the last navigation data reported by the drone.
+func (m *Minidrone) Telemetry() Telemetry {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	return m.telemetry
+}
+
+// processNotification decodes a notification received on any of the drone
+// notification characteristics.
+func (m *Minidrone) processNotification(data []byte) {
+	f, ok := parseFrame(data)
+	if !ok {
+		// ignore, just a sync
+		return
+	}
+
+	switch {
+	case f.project == projectCommon && f.class == classSettingsState:
+		m.processSettingsState(f)
+	case f.project == projectCommon && f.class == classCalibrationState:
+		m.processCalibrationState(f)
+	case f.project == projectCommon && f.class == classChargerState:
+		m.processChargerState(f)
+	case f.project == projectCommon && f.class == classCommonState:
+		m.processCommonState(f)
+	case f.project == projectMinidrone && f.class == classPilotingState:
+		m.processPilotingState(f)
+	case f.project == projectMinidrone && f.class == classPilotingSettingsState:
+		m.processPilotingSettingsState(f)
+	case f.project == projectMinidrone && f.class == classSpeedSettingsState:
+		m.processSpeedSettingsState(f)
+	case f.project == projectMinidrone && f.class == classUsbAccessoryState:
+		m.processUsbAccessoryState(f)
+	case f.project == projectMinidrone && f.class == classNavigationDataState:
+		m.processNavigationData(f)
+	}
+}
+
+func (m *Minidrone) processCommonState(f frame) {
+	switch f.command {
+	case cmdAllStatesChanged:
+		m.publish(AllStatesReceived, nil)
+
+	case cmdBatteryStateChanged:
+		if !f.argsLen(1) {
+			return
+		}
+		level := int(f.args[0])
+		if debug {
+			println("battery", level)
+		}
+
+		m.stateMutex.Lock()
+		m.battery = level
+		m.stateMutex.Unlock()
+
+		m.publish(Battery, level)
+		m.checkBattery(level)
+	}
+}
+
+func (m *Minidrone) processNavigationData(f frame) {
+	switch f.command {
+	case cmdDronePosition:
+		if !f.argsLen(8) {
+			return
+		}
+		pos := Position{
+			X:   f.int16At(0),
+			Y:   f.int16At(2),
+			Z:   f.int16At(4),
+			Psi: f.int16At(6),
+		}
+
+		m.stateMutex.Lock()
+		m.telemetry.Position = pos
+		m.positionKnown = true
+		m.stateMutex.Unlock()
+
+		if m.listening() {
+			m.publish(PositionChange, pos)
+		}
+		m.checkTurn(pos.Psi)
+
+	case cmdDroneSpeed:
+		if !f.argsLen(6) {
+			return
+		}
+		speed := Speed{
+			X: f.int16At(0),
+			Y: f.int16At(2),
+			Z: f.int16At(4),
+		}
+
+		m.stateMutex.Lock()
+		m.telemetry.Speed = speed
+		m.stateMutex.Unlock()
+
+		if m.listening() {
+			m.publish(SpeedChange, speed)
+		}
+
+	case cmdDroneAltitude:
+		if !f.argsLen(4) {
+			return
+		}
+		altitude := int32(f.uint32At(0))
+
+		m.stateMutex.Lock()
+		m.telemetry.Altitude = altitude
+		m.stateMutex.Unlock()
+
+		if m.listening() {
+			m.publish(AltitudeChange, altitude)
+		}
+
+	case cmdDroneQuaternion:
+		if !f.argsLen(16) {
+			return
+		}
+		att := Attitude{
+			W: f.float32At(0),
+			X: f.float32At(4),
+			Y: f.float32At(8),
+			Z: f.float32At(12),
+		}
+
+		m.stateMutex.Lock()
+		m.telemetry.Attitude = att
+		m.stateMutex.Unlock()
+
+		if m.listening() {
+			m.publish(AttitudeChange, att)
+		}
+	}
+}
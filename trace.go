@@ -0,0 +1,79 @@
+package minidrone
+
+// TraceDirection is the direction of a traced frame.
+type TraceDirection int
+
+const (
+	TraceSent TraceDirection = iota
+	TraceReceived
+)
+
+func (d TraceDirection) String() string {
+	if d == TraceSent {
+		return "sent"
+	}
+
+	return "received"
+}
+
+// TraceFunc receives every raw frame sent to and received from the drone,
+// with the characteristic it went through, such as "fa0b". The payload is
+// only valid during the call.
+type TraceFunc func(direction TraceDirection, characteristic string, payload []byte)
+
+// WithTrace calls trace for every raw frame sent to and received from the
+// drone, including command retries and received acks, to diagnose protocol
+// issues. The acks that the transport sends for notifications are not traced.
+func WithTrace(trace TraceFunc) Option {
+	return func(m *Minidrone) {
+		m.trace = trace
+	}
+}
+
+// characteristic names of the channels, after the BLE characteristics
+const (
+	tracePcmd         = "fa0a"
+	traceCommand      = "fa0b"
+	tracePriority     = "fa0c"
+	traceStatus       = "fb0e"
+	traceData         = "fb0f"
+	traceCommandAck   = "fb1b"
+	tracePriorityData = "fb1c"
+)
+
+func (ch Channel) characteristic() string {
+	switch ch {
+	case ChannelStatus:
+		return traceStatus
+	case ChannelData:
+		return traceData
+	case ChannelCommandAck:
+		return traceCommandAck
+	case ChannelPriority:
+		return tracePriorityData
+	}
+
+	return ""
+}
+
+func (m *Minidrone) traceFrame(direction TraceDirection, characteristic string, payload []byte) {
+	if m.trace != nil {
+		m.trace(direction, characteristic, payload)
+	}
+}
+
+// sendCommand, sendPriority and sendPcmd write a frame to the transport.
+func (m *Minidrone) sendCommand(buf []byte) error {
+	m.traceFrame(TraceSent, traceCommand, buf)
+	return m.transport.WriteCommand(buf)
+}
+
+func (m *Minidrone) sendPriority(buf []byte) error {
+	m.traceFrame(TraceSent, tracePriority, buf)
+	return m.transport.WritePriority(buf)
+}
+
+func (m *Minidrone) sendPcmd(buf []byte) error {
+	m.traceFrame(TraceSent, tracePcmd, buf)
+	return m.transport.WritePcmd(buf)
+}
@@ -0,0 +1,66 @@
+package minidrone
+
+import "context"
+
+// Channel is the channel a frame was received on.
+type Channel int
+
+const (
+	// ChannelStatus carries notifications that require an ack, such as the
+	// flying state.
+	ChannelStatus Channel = iota
+
+	// ChannelData carries notifications that do not require an ack, such as
+	// the battery level.
+	ChannelData
+
+	// ChannelCommandAck carries the acks for the frames written with
+	// WriteCommand.
+	ChannelCommandAck
+
+	// ChannelPriority carries high priority notifications, such as
+	// emergencies and critical alerts.
+	ChannelPriority
+)
+
+// Transport carries ARSDK frames between the Minidrone and the drone. Frames
+// always start with their data type and sequence number, followed by the
+// command for data frames or by the acknowledged sequence number for acks.
+type Transport interface {
+	// Connect establishes the link to the drone, giving up when ctx is done
+	// first.
+	Connect(ctx context.Context) error
+
+	// Subscribe enables the notifications of the drone, which are passed to
+	// handler with the channel they were received on. The transport
+	// acknowledges the notifications that require it.
+	Subscribe(handler func(ch Channel, frame []byte)) error
+
+	// WriteCommand sends a command frame.
+	WriteCommand(frame []byte) error
+
+	// WritePcmd sends a pcmd frame.
+	WritePcmd(frame []byte) error
+
+	// WritePriority sends a frame on the high priority channel.
+	WritePriority(frame []byte) error
+
+	// Disconnect closes the link to the drone.
+	Disconnect() error
+}
+
+// processFrame handles a frame received from the transport.
+func (m *Minidrone) processFrame(ch Channel, frame []byte) {
+	m.notificationReceived()
+	m.traceFrame(TraceReceived, ch.characteristic(), frame)
+	if m.recorder != nil {
+		m.recorder.recordNotification(ch, frame)
+	}
+
+	if ch == ChannelCommandAck {
+		m.processCommandAck(frame)
+		return
+	}
+
+	m.processNotification(frame)
+}
@@ -0,0 +1,313 @@
+package minidrone
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTransport is an in-memory Transport that records the frames written to
+// it and confirms flat trims like a drone would.
+type fakeTransport struct {
+	mu        sync.Mutex
+	handler   func(ch Channel, frame []byte)
+	connected bool
+	commands  [][]byte
+	pcmds     [][]byte
+	priority  [][]byte
+
+	// onCommand, if set, is called with each command frame once written
+	onCommand func(frame []byte)
+
+	// pcmdErr, if set, is returned by WritePcmd
+	pcmdErr error
+
+	// noFlatTrim, if set, leaves the flat trims unconfirmed
+	noFlatTrim bool
+
+	// discardPcmds, if set, makes WritePcmd count the frames in pcmdCount
+	// instead of recording them, so that it does not allocate
+	discardPcmds bool
+	pcmdCount    int
+}
+
+func (t *fakeTransport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.connected = true
+	return nil
+}
+
+func (t *fakeTransport) Subscribe(handler func(ch Channel, frame []byte)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handler = handler
+	return nil
+}
+
+func (t *fakeTransport) WriteCommand(frame []byte) error {
+	t.mu.Lock()
+	t.commands = append(t.commands, append([]byte(nil), frame...))
+	handler, onCommand, noFlatTrim := t.handler, t.onCommand, t.noFlatTrim
+	t.mu.Unlock()
+
+	if onCommand != nil {
+		onCommand(frame)
+	}
+
+	// answer a flat trim with FlatTrimChanged
+	if handler != nil && !noFlatTrim && bytes.Equal(frame[2:6], []byte{projectMinidrone, 0x00, 0x00, 0x00}) {
+		handler(ChannelStatus, []byte{frameTypeDataWithAck, 1, projectMinidrone, classPilotingState, cmdFlatTrimChanged, 0x00})
+	}
+
+	return nil
+}
+
+func (t *fakeTransport) WritePcmd(frame []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pcmdErr != nil {
+		return t.pcmdErr
+	}
+	if t.discardPcmds {
+		t.pcmdCount++
+		return nil
+	}
+
+	t.pcmds = append(t.pcmds, append([]byte(nil), frame...))
+	return nil
+}
+
+func (t *fakeTransport) WritePriority(frame []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.priority = append(t.priority, append([]byte(nil), frame...))
+	return nil
+}
+
+func (t *fakeTransport) Disconnect() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.connected = false
+	return nil
+}
+
+// command returns the last command frame written for project, class and cmd.
+func (t *fakeTransport) command(project, class, cmd byte) []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := len(t.commands) - 1; i >= 0; i-- {
+		c := t.commands[i]
+		if len(c) >= 6 && c[2] == project && c[3] == class && c[4] == cmd && c[5] == 0x00 {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// count returns how many command frames were written for project, class
+// and cmd.
+func (t *fakeTransport) count(project, class, cmd byte) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, c := range t.commands {
+		if len(c) >= 6 && c[2] == project && c[3] == class && c[4] == cmd && c[5] == 0x00 {
+			n++
+		}
+	}
+
+	return n
+}
+
+// notify passes a notification from the drone with the args to the handler.
+func (t *fakeTransport) notify(project, class, cmd byte, args ...byte) {
+	t.mu.Lock()
+	handler := t.handler
+	t.mu.Unlock()
+
+	handler(ChannelStatus, append([]byte{frameTypeData, 1, project, class, cmd, 0x00}, args...))
+}
+
+func startFake(t *testing.T, opts ...Option) (*Minidrone, *fakeTransport) {
+	t.Helper()
+
+	ft := &fakeTransport{}
+	m := NewMinidroneTransport(ft, append([]Option{WithManualTick()}, opts...)...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := m.StartContext(ctx)
+	if err != nil {
+		t.Fatalf("StartContext() = %v", err)
+	}
+
+	return m, ft
+}
+
+func TestStartContext(t *testing.T) {
+	m, ft := startFake(t)
+
+	if !ft.connected {
+		t.Fatal("transport is not connected")
+	}
+	if ft.handler == nil {
+		t.Fatal("notifications are not subscribed")
+	}
+
+	// Init sends the date, then asks for all settings and states
+	for _, want := range [][3]byte{
+		{projectCommon, 0x04, 0x01},
+		{projectCommon, 0x02, 0x00},
+		{projectCommon, 0x04, 0x00},
+		{projectMinidrone, 0x00, 0x00},
+	} {
+		if ft.command(want[0], want[1], want[2]) == nil {
+			t.Errorf("no command % x sent", want)
+		}
+	}
+
+	// sequence numbers follow each other from the start of the connection
+	for i, c := range ft.commands {
+		if c[1] != byte(i+1) {
+			t.Errorf("command %d has sequence number %d", i, c[1])
+		}
+	}
+
+	m.Tick()
+	if len(ft.pcmds) != 1 || len(ft.pcmds[0]) != pcmdLen {
+		t.Errorf("Tick() wrote pcmds %x", ft.pcmds)
+	}
+}
+
+func TestStartContextDeadline(t *testing.T) {
+	ft := &fakeTransport{noFlatTrim: true}
+	m := NewMinidroneTransport(ft, WithManualTick())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := m.StartContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("StartContext() = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestTakeOff(t *testing.T) {
+	m, ft := startFake(t)
+
+	err := m.TakeOff()
+	if err != nil {
+		t.Fatalf("TakeOff() = %v", err)
+	}
+
+	c := ft.command(projectMinidrone, 0x00, 0x01)
+	if c == nil {
+		t.Fatal("no takeoff command sent")
+	}
+	if c[0] != frameTypeData || len(c) != 6 {
+		t.Errorf("takeoff frame = % x", c)
+	}
+}
+
+func TestTakeOffNotArmed(t *testing.T) {
+	m, ft := startFake(t, WithClassroomMode())
+
+	err := m.TakeOff()
+	if err != ErrNotArmed {
+		t.Fatalf("TakeOff() = %v, want ErrNotArmed", err)
+	}
+	if ft.command(projectMinidrone, 0x00, 0x01) != nil {
+		t.Error("takeoff command sent while disarmed")
+	}
+
+	m.Arm()
+	err = m.TakeOff()
+	if err != nil {
+		t.Fatalf("TakeOff() after Arm = %v", err)
+	}
+	if ft.command(projectMinidrone, 0x00, 0x01) == nil {
+		t.Error("no takeoff command sent after Arm")
+	}
+}
+
+func TestManualTickNoGoroutines(t *testing.T) {
+	m, ft := startFake(t, WithMaxFlightTime(time.Millisecond), WithStateRefresh(time.Millisecond))
+
+	m.stateMutex.Lock()
+	m.flightStart = time.Now().Add(-time.Second)
+	m.stateMutex.Unlock()
+	states := ft.count(projectCommon, 0x04, 0x00)
+	time.Sleep(2 * time.Millisecond)
+
+	n := runtime.NumGoroutine()
+	m.Tick()
+	if got := runtime.NumGoroutine(); got > n {
+		t.Errorf("Tick() started %d goroutines", got-n)
+	}
+
+	// the landing and refresh are sent before Tick returns
+	if ft.command(projectMinidrone, 0x00, 0x03) == nil {
+		t.Error("no land command sent")
+	}
+	if ft.count(projectCommon, 0x04, 0x00) != states+1 {
+		t.Error("no state refresh sent")
+	}
+}
+
+func TestTurnAndWait(t *testing.T) {
+	m, ft := startFake(t)
+
+	// the drone reports its new heading as soon as it gets the turn
+	done := make(chan error, 1)
+	go func() {
+		done <- m.TurnAndWait(context.Background(), 90)
+	}()
+
+	for ft.command(projectMinidrone, 0x04, 0x01) == nil {
+		time.Sleep(time.Millisecond)
+	}
+	ft.handler(ChannelStatus, []byte{frameTypeData, 1, projectMinidrone, classNavigationDataState, cmdDronePosition, 0x00,
+		0, 0, 0, 0, 0, 0, 90, 0})
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("TurnAndWait() = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TurnAndWait() did not return")
+	}
+}
+
+func TestTurnAndWaitTimeout(t *testing.T) {
+	m, _ := startFake(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := m.TurnAndWait(ctx, 90)
+	if err != context.DeadlineExceeded {
+		t.Errorf("TurnAndWait() = %v, want DeadlineExceeded", err)
+	}
+
+	m.stateMutex.Lock()
+	pending := m.turnPending
+	m.stateMutex.Unlock()
+	if pending {
+		t.Error("turn still pending after the timeout")
+	}
+}
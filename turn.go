@@ -0,0 +1,84 @@
+package minidrone
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// turnTolerance is how close in degrees the reported heading has to be to
+// the target for a turn to be complete.
+const turnTolerance = 5
+
+// turnTimeout is how long a turn may take before it is given up on, and no
+// TurnComplete event is published for it anymore.
+const turnTimeout = 10 * time.Second
+
+// TurnDegrees turns the drone by deg degrees relative to its current heading,
+// clockwise for positive values, from -180 to 180. A TurnComplete event with
+// the new heading is published once the drone reports that it has reached
+// it, which requires a firmware that sends position notifications. The turn
+// is given up on when the drone lands or has not reached its new heading
+// after 10 seconds.
+func (m *Minidrone) TurnDegrees(deg float32) error {
+	if deg > 180 {
+		deg = 180
+	} else if deg < -180 {
+		deg = -180
+	}
+
+	m.stateMutex.Lock()
+	m.turnTarget = normalizeHeading(int(m.telemetry.Position.Psi) + int(deg))
+	m.turnPending = true
+	m.turnDeadline = time.Now().Add(turnTimeout)
+	m.stateMutex.Unlock()
+
+	buf := []byte{0x02, 0x00, 0x02, 0x04, 0x01, 0x00, 0x00, 0x00}
+	binary.LittleEndian.PutUint16(buf[6:], uint16(int16(deg)))
+	return m.writeCommand("turn", buf)
+}
+
+// checkTurn publishes TurnComplete when a pending turn has reached its target.
+func (m *Minidrone) checkTurn(psi int16) {
+	m.stateMutex.Lock()
+	if m.turnPending && time.Now().After(m.turnDeadline) {
+		m.turnPending = false
+	}
+	done := m.turnPending && headingDiff(int(psi), m.turnTarget) <= turnTolerance
+	if done {
+		m.turnPending = false
+	}
+	m.stateMutex.Unlock()
+
+	if done {
+		m.publish(TurnComplete, psi)
+	}
+}
+
+// cancelTurn gives up on the pending turn, if any.
+func (m *Minidrone) cancelTurn() {
+	m.stateMutex.Lock()
+	m.turnPending = false
+	m.stateMutex.Unlock()
+}
+
+// normalizeHeading wraps deg into -180..180.
+func normalizeHeading(deg int) int {
+	for deg > 180 {
+		deg -= 360
+	}
+	for deg < -180 {
+		deg += 360
+	}
+
+	return deg
+}
+
+// headingDiff returns the absolute difference between two headings.
+func headingDiff(a, b int) int {
+	d := normalizeHeading(a - b)
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
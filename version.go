@@ -0,0 +1,80 @@
+package minidrone
+
+import (
+	"runtime"
+	rdebug "runtime/debug"
+	"sync"
+)
+
+const (
+	modulePath    = "github.com/hybridgroup/tinygo-minidrone"
+	bluetoothPath = "tinygo.org/x/bluetooth"
+
+	// unknownVersion is reported when the program was built without module
+	// information, such as by some TinyGo versions.
+	unknownVersion = "unknown"
+)
+
+var (
+	buildInfo     VersionInfo
+	buildInfoOnce sync.Once
+)
+
+// VersionInfo identifies exactly which driver build is running. The versions
+// are the module versions recorded in the build info of the program, such as
+// "v0.3.0" for a tagged release, "(devel)" when the driver is built from a
+// local checkout, or "unknown" when the build info is not available.
+type VersionInfo struct {
+	// Driver is the module version of this driver.
+	Driver string
+
+	// Bluetooth is the module version of the tinygo.org/x/bluetooth package
+	// used to talk to the drone.
+	Bluetooth string
+
+	// Runtime is the Go or TinyGo version the program was built with.
+	Runtime string
+}
+
+// Version returns the module version of this driver, as with
+// BuildInfo().Driver.
+func Version() string {
+	return BuildInfo().Driver
+}
+
+// BuildInfo returns the versions of the driver, of the bluetooth package and
+// of the runtime of the running program.
+func BuildInfo() VersionInfo {
+	buildInfoOnce.Do(func() {
+		buildInfo = readBuildInfo()
+	})
+
+	return buildInfo
+}
+
+func readBuildInfo() VersionInfo {
+	info := VersionInfo{
+		Driver:    unknownVersion,
+		Bluetooth: unknownVersion,
+		Runtime:   runtime.Version(),
+	}
+
+	bi, ok := rdebug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if bi.Main.Path == modulePath {
+		info.Driver = bi.Main.Version
+	}
+	for _, dep := range bi.Deps {
+		switch dep.Path {
+		case modulePath:
+			info.Driver = dep.Version
+		case bluetoothPath:
+			info.Bluetooth = dep.Version
+		}
+	}
+
+	return info
+}
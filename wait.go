@@ -0,0 +1,195 @@
+package minidrone
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// flatTrimTimeout is how long Start waits for the drone to confirm the
+// flat trim before carrying on without it.
+const flatTrimTimeout = 2 * time.Second
+
+// waitFlyingState blocks until the drone reports one of the flying states,
+// or ctx is done.
+func (m *Minidrone) waitFlyingState(ctx context.Context, states ...FlyingState) error {
+	for {
+		m.stateMutex.Lock()
+		current, changed := m.FlyingState(), m.stateChanged
+		m.stateMutex.Unlock()
+
+		for _, state := range states {
+			if current == state {
+				return nil
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ErrEmergency is returned when the drone goes into emergency while waiting
+// for it to reach a flying state.
+var ErrEmergency = errors.New("drone is in emergency")
+
+// TakeOffAndWait tells the Minidrone to takeoff and waits until it reports
+// that it is hovering. Use a context with a timeout to limit how long it
+// waits.
+func (m *Minidrone) TakeOffAndWait(ctx context.Context) error {
+	err := m.TakeOff()
+	if err != nil {
+		return err
+	}
+
+	return m.waitFlying(ctx, FlyingStateHovering, FlyingStateFlying)
+}
+
+// LandAndWait tells the Minidrone to land and waits until it reports that it
+// has landed. Use a context with a timeout to limit how long it waits.
+func (m *Minidrone) LandAndWait(ctx context.Context) error {
+	err := m.Land()
+	if err != nil {
+		return err
+	}
+
+	return m.waitFlying(ctx, FlyingStateLanded)
+}
+
+// waitFlying is like waitFlyingState, but returns ErrEmergency if the drone
+// goes into emergency first.
+func (m *Minidrone) waitFlying(ctx context.Context, states ...FlyingState) error {
+	err := m.waitFlyingState(ctx, append(states, FlyingStateEmergency)...)
+	if err != nil {
+		return err
+	}
+
+	if m.Status().FlyingState == FlyingStateEmergency {
+		return ErrEmergency
+	}
+
+	return nil
+}
+
+// FlatTrimAndWait tells the Minidrone to calibrate itself as level and waits
+// until it confirms the new trim. Use a context with a timeout to limit how
+// long it waits.
+func (m *Minidrone) FlatTrimAndWait(ctx context.Context) error {
+	m.stateMutex.Lock()
+	trimmed := m.flatTrimmed
+	m.stateMutex.Unlock()
+
+	err := m.FlatTrim()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-trimmed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sleepContext sleeps for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type eventWaiter struct {
+	event string
+	data  chan interface{}
+}
+
+// WaitEvent blocks until the Minidrone publishes event, such as
+// TurnComplete, and returns its data. It does not replace the handler set
+// with OnEvent. Use a context with a timeout to limit how long it waits.
+func (m *Minidrone) WaitEvent(ctx context.Context, event string) (interface{}, error) {
+	w := m.addWaiter(event)
+	defer m.removeWaiter(w)
+
+	select {
+	case data := <-w.data:
+		return data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TurnAndWait turns the drone by deg degrees as with TurnDegrees, and waits
+// until it publishes TurnComplete. The turn is given up on when ctx is done
+// first. Use a context with a timeout to limit how long it waits.
+func (m *Minidrone) TurnAndWait(ctx context.Context, deg float32) error {
+	// wait for the event before the turn is sent, so that it cannot be missed
+	w := m.addWaiter(TurnComplete)
+	defer m.removeWaiter(w)
+
+	err := m.TurnDegrees(deg)
+	if err != nil {
+		m.cancelTurn()
+		return err
+	}
+
+	select {
+	case <-w.data:
+		return nil
+	case <-ctx.Done():
+		m.cancelTurn()
+		return ctx.Err()
+	}
+}
+
+func (m *Minidrone) addWaiter(event string) *eventWaiter {
+	w := &eventWaiter{event: event, data: make(chan interface{}, 1)}
+
+	m.eventMutex.Lock()
+	m.eventWaiters = append(m.eventWaiters, w)
+	m.eventMutex.Unlock()
+
+	return w
+}
+
+func (m *Minidrone) removeWaiter(w *eventWaiter) {
+	m.eventMutex.Lock()
+	defer m.eventMutex.Unlock()
+
+	for i, waiter := range m.eventWaiters {
+		if waiter == w {
+			m.eventWaiters = append(m.eventWaiters[:i], m.eventWaiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// notifyWaiters passes an event to the WaitEvent calls waiting for it.
+func (m *Minidrone) notifyWaiters(event string, data interface{}) {
+	m.eventMutex.Lock()
+	defer m.eventMutex.Unlock()
+
+	for _, w := range m.eventWaiters {
+		if w.event != event {
+			continue
+		}
+
+		select {
+		case w.data <- data:
+		default:
+		}
+	}
+}
@@ -0,0 +1,325 @@
+//go:build !baremetal
+
+package minidrone
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultWiFiHost is the address of a Mambo FPV on its own access point.
+const DefaultWiFiHost = "192.168.99.3"
+
+const (
+	wifiDiscoveryPort    = 44444
+	wifiD2CPort          = 43210
+	wifiHandshakeTimeout = 5 * time.Second
+	wifiControllerType   = "computer"
+	wifiControllerName   = "tinygo-minidrone"
+
+	wifiHeaderLen   = 7
+	wifiMaxFrameLen = 4096
+
+	wifiMaxReadBackoff = time.Second
+)
+
+// ARNetworkAL buffers
+const (
+	wifiBufferPing        = 0x00
+	wifiBufferPong        = 0x01
+	wifiBufferPcmd        = 0x0a
+	wifiBufferCommand     = 0x0b
+	wifiBufferPriority    = 0x0c
+	wifiBufferDataWithAck = 0x7e
+	wifiBufferData        = 0x7f
+
+	// acks for a buffer are sent on the buffer id plus wifiBufferAckOffset
+	wifiBufferAckOffset  = 0x80
+	wifiBufferCommandAck = wifiBufferAckOffset + wifiBufferCommand
+)
+
+// ErrHandshake is returned when the drone refuses the Wi-Fi connection.
+var ErrHandshake = errors.New("drone refused the wifi connection")
+
+// WiFiTransport is the Transport for a drone connected over Wi-Fi, such as
+// the Mambo FPV. It connects with the ARSDK discovery handshake on TCP and
+// sends the commands over UDP, which has a lower latency and a longer range
+// than Bluetooth LE.
+type WiFiTransport struct {
+	// Host is the address of the drone.
+	Host string
+
+	// D2CPort is the local UDP port on which the drone sends notifications.
+	D2CPort int
+
+	conn  *net.UDPConn
+	drone *net.UDPAddr
+
+	writeMutex sync.Mutex
+	seqs       [256]uint8
+	buf        []byte
+	done       chan struct{}
+}
+
+// NewWiFiTransport returns a new WiFiTransport for the drone at host, such
+// as DefaultWiFiHost.
+func NewWiFiTransport(host string) *WiFiTransport {
+	return &WiFiTransport{
+		Host:    host,
+		D2CPort: wifiD2CPort,
+		buf:     make([]byte, 0, wifiMaxFrameLen),
+	}
+}
+
+type wifiHandshakeRequest struct {
+	ControllerType string `json:"controller_type"`
+	ControllerName string `json:"controller_name"`
+	D2CPort        int    `json:"d2c_port"`
+}
+
+type wifiHandshakeResponse struct {
+	Status  int `json:"status"`
+	C2DPort int `json:"c2d_port"`
+}
+
+// Connect runs the discovery handshake with the drone and opens the UDP
+// connection to it. A previous connection is closed first, so that Connect
+// can be called again to reconnect.
+func (t *WiFiTransport) Connect(ctx context.Context) error {
+	t.Disconnect()
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: t.D2CPort})
+	if err != nil {
+		return err
+	}
+
+	drone, err := t.dial(ctx)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	t.writeMutex.Lock()
+	t.conn = conn
+	t.drone = drone
+	t.seqs = [256]uint8{}
+	t.writeMutex.Unlock()
+
+	return nil
+}
+
+// dial runs the handshake and returns the address the drone listens on for
+// commands.
+func (t *WiFiTransport) dial(ctx context.Context) (*net.UDPAddr, error) {
+	c2d, err := t.handshake(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return net.ResolveUDPAddr("udp", net.JoinHostPort(t.Host, strconv.Itoa(c2d)))
+}
+
+// handshake tells the drone which port to send notifications to, and
+// returns the port it listens on for commands.
+func (t *WiFiTransport) handshake(ctx context.Context) (int, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(t.Host, strconv.Itoa(wifiDiscoveryPort)))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(wifiHandshakeTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	err = json.NewEncoder(conn).Encode(wifiHandshakeRequest{
+		ControllerType: wifiControllerType,
+		ControllerName: wifiControllerName,
+		D2CPort:        t.D2CPort,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// the response is a JSON object terminated by a null byte
+	var resp []byte
+	buf := make([]byte, 512)
+	for {
+		n, err := conn.Read(buf)
+		resp = append(resp, buf[:n]...)
+		if i := bytes.IndexByte(resp, 0); i >= 0 {
+			resp = resp[:i]
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var r wifiHandshakeResponse
+	err = json.Unmarshal(resp, &r)
+	switch {
+	case err != nil:
+		return 0, err
+	case r.Status != 0 || r.C2DPort == 0:
+		return 0, ErrHandshake
+	}
+
+	if debug {
+		println("wifi handshake complete, c2d port", r.C2DPort)
+	}
+
+	return r.C2DPort, nil
+}
+
+// Subscribe starts receiving the notifications of the drone.
+func (t *WiFiTransport) Subscribe(handler func(ch Channel, frame []byte)) error {
+	if t.conn == nil {
+		return errors.New("wifi transport is not connected")
+	}
+
+	t.done = make(chan struct{})
+	go t.receive(t.conn, handler, t.done)
+
+	return nil
+}
+
+func (t *WiFiTransport) receive(conn *net.UDPConn, handler func(ch Channel, frame []byte), done chan struct{}) {
+	buf := make([]byte, wifiMaxFrameLen)
+	backoff := time.Duration(0)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if debug {
+				println("wifi read error", err.Error())
+			}
+
+			// back off on errors that persist, instead of spinning
+			backoff = backoff*2 + 10*time.Millisecond
+			if backoff > wifiMaxReadBackoff {
+				backoff = wifiMaxReadBackoff
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(backoff):
+			}
+			continue
+		}
+		backoff = 0
+
+		// a datagram can carry several frames
+		data := buf[:n]
+		for len(data) >= wifiHeaderLen {
+			size := int(binary.LittleEndian.Uint32(data[3:7]))
+			if size < wifiHeaderLen || size > len(data) {
+				break
+			}
+			t.dispatch(handler, data[0], data[1], data[2], data[wifiHeaderLen:size])
+			data = data[size:]
+		}
+	}
+}
+
+// dispatch passes a received frame to handler in the same form as the BLE
+// frames, starting with its data type and sequence number.
+func (t *WiFiTransport) dispatch(handler func(ch Channel, frame []byte), dataType, id, seq byte, payload []byte) {
+	frame := append([]byte{dataType, seq}, payload...)
+
+	switch id {
+	case wifiBufferPing:
+		t.write(frameTypeData, wifiBufferPong, payload)
+	case wifiBufferData:
+		handler(ChannelData, frame)
+	case wifiBufferDataWithAck:
+		t.write(frameTypeAck, wifiBufferAckOffset+wifiBufferDataWithAck, []byte{seq})
+		handler(ChannelStatus, frame)
+	case wifiBufferCommandAck:
+		handler(ChannelCommandAck, frame)
+	}
+}
+
+// write sends payload on the buffer id with the next sequence number of the
+// buffer.
+func (t *WiFiTransport) write(dataType, id byte, payload []byte) error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	t.seqs[id]++
+	return t.writeFrame(dataType, id, t.seqs[id], payload)
+}
+
+// writeFrame must be called with the writeMutex held.
+func (t *WiFiTransport) writeFrame(dataType, id, seq byte, payload []byte) error {
+	if t.conn == nil {
+		return errors.New("wifi transport is not connected")
+	}
+
+	buf := append(t.buf[:0], dataType, id, seq, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(buf[3:7], uint32(wifiHeaderLen+len(payload)))
+	buf = append(buf, payload...)
+	t.buf = buf
+
+	_, err := t.conn.WriteToUDP(buf, t.drone)
+	return err
+}
+
+// writeBLEFrame sends a frame in the BLE form on the buffer id, keeping its
+// data type and sequence number.
+func (t *WiFiTransport) writeBLEFrame(id byte, frame []byte) error {
+	if len(frame) < 2 {
+		return errors.New("frame is too short")
+	}
+
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	return t.writeFrame(frame[0], id, frame[1], frame[2:])
+}
+
+// WriteCommand sends a command frame on the command buffer.
+func (t *WiFiTransport) WriteCommand(frame []byte) error {
+	return t.writeBLEFrame(wifiBufferCommand, frame)
+}
+
+// WritePcmd sends a pcmd frame on the pcmd buffer.
+func (t *WiFiTransport) WritePcmd(frame []byte) error {
+	return t.writeBLEFrame(wifiBufferPcmd, frame)
+}
+
+// WritePriority sends a frame on the emergency buffer.
+func (t *WiFiTransport) WritePriority(frame []byte) error {
+	return t.writeBLEFrame(wifiBufferPriority, frame)
+}
+
+// Disconnect closes the UDP connection.
+func (t *WiFiTransport) Disconnect() error {
+	if t.done != nil {
+		close(t.done)
+		t.done = nil
+	}
+
+	t.writeMutex.Lock()
+	conn := t.conn
+	t.conn = nil
+	t.writeMutex.Unlock()
+
+	if conn == nil {
+		return nil
+	}
+
+	return conn.Close()
+}